@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/globusclient"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/httpclient"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/serviceuser"
+)
+
+// Scope of the globus transfer API, which the data_access scopes of collections depend on
+const transferScope = "urn:globus:auth:scope:transfer.api.globus.org:all"
+
+type checkStatus string
+
+const (
+	checkOk   checkStatus = "OK"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+)
+
+// The result of a single check, with a hint on how to fix it
+type checkResult struct {
+	status checkStatus
+	name   string
+	detail string
+	hint   string
+}
+
+// Checks of one part of the setup
+type checkSection struct {
+	title  string
+	checks []checkResult
+}
+
+func (s *checkSection) add(status checkStatus, name string, detail string, hint string) {
+	s.checks = append(s.checks, checkResult{status: status, name: name, detail: detail, hint: hint})
+}
+
+// Check the connection to SciCat and Globus with the configured credentials,
+// and print a report per facility. Returns the exit code.
+func doctor(configPath string, args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	flags.StringVar(&configPath, "config", configPath, "path of the config file")
+	timeout := flags.Duration("timeout", time.Minute, "timeout of all checks")
+	_ = flags.Parse(args)
+
+	confPath, err := config.FindConfigFile(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't find config: %v\n", err)
+		return 1
+	}
+	conf, err := config.ReadConfigFile(confPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: invalid config: %v\n", confPath, err)
+		return 1
+	}
+
+	transport, err := httpclient.NewTransport(conf.Outbound)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: invalid outbound config: %v\n", confPath, err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	sections := []checkSection{checkScicat(ctx, conf, transport)}
+	globusSection, client := checkGlobus(ctx, conf, transport)
+	sections = append(sections, globusSection)
+	for _, facility := range conf.Facilities {
+		sections = append(sections, checkFacility(ctx, facility, client, transport))
+	}
+
+	failed := 0
+	for _, section := range sections {
+		fmt.Println(section.title)
+		for _, check := range section.checks {
+			line := fmt.Sprintf("  [%-4s] %s", check.status, check.name)
+			if check.detail != "" {
+				line += ": " + check.detail
+			}
+			fmt.Println(line)
+			if check.hint != "" && check.status != checkOk {
+				fmt.Printf("         -> %s\n", check.hint)
+			}
+			if check.status == checkFail {
+				failed++
+			}
+		}
+		fmt.Println()
+	}
+	if failed > 0 {
+		fmt.Printf("%d check(s) failed\n", failed)
+		return 1
+	}
+	fmt.Println("All checks passed")
+	return 0
+}
+
+// Log in as the service user and look for the job type of transfers
+func checkScicat(ctx context.Context, conf config.Config, transport http.RoundTripper) checkSection {
+	section := checkSection{title: fmt.Sprintf("SciCat (%s)", conf.ScicatUrl)}
+	username := os.Getenv("SCICAT_SERVICE_USER_USERNAME")
+	password := os.Getenv("SCICAT_SERVICE_USER_PASSWORD")
+	if username == "" || password == "" {
+		section.add(checkFail, "service user login", "credentials are not set", "set SCICAT_SERVICE_USER_USERNAME and SCICAT_SERVICE_USER_PASSWORD")
+		return section
+	}
+
+	scicatClient := scicat.NewClient(conf.ScicatUrl, conf.Scicat, transport)
+	serviceUser, err := serviceuser.CreateServiceUser(conf.ScicatUrl, username, password, scicatClient.HttpClient())
+	if err != nil {
+		section.add(checkFail, "service user login", err.Error(), fmt.Sprintf("check that scicatUrl points to the SciCat backend and that the password of '%s' is correct", username))
+		return section
+	}
+	section.add(checkOk, "service user login", "logged in as "+username, "")
+
+	token, err := serviceUser.GetToken()
+	if err != nil {
+		section.add(checkFail, "globus_transfer_job job type", err.Error(), "")
+		return section
+	}
+	filter, _ := json.Marshal(map[string]any{
+		"where":  map[string]any{"type": "globus_transfer_job"},
+		"limits": map[string]any{"limit": 1},
+	})
+	existing, err := scicatClient.GetJobList(ctx, token, string(filter))
+	switch {
+	case err != nil:
+		section.add(checkFail, "globus_transfer_job job type", err.Error(), "check that the service user may read jobs")
+	case len(existing) == 0:
+		section.add(checkWarn, "globus_transfer_job job type", "no job of this type exists yet", "make sure 'globus_transfer_job' is defined in the jobConfig of SciCat and that the service user may create it")
+	default:
+		section.add(checkOk, "globus_transfer_job job type", "jobs of this type exist", "")
+	}
+	return section
+}
+
+// Get a token for the transfer API and list the scopes granted to the service account
+func checkGlobus(ctx context.Context, conf config.Config, transport http.RoundTripper) (checkSection, *globusclient.Client) {
+	section := checkSection{title: "Globus"}
+	clientId := os.Getenv("GLOBUS_CLIENT_ID")
+	clientSecret := os.Getenv("GLOBUS_CLIENT_SECRET")
+	if clientId == "" || clientSecret == "" {
+		section.add(checkFail, "service account token", "credentials are not set", "set GLOBUS_CLIENT_ID and GLOBUS_CLIENT_SECRET")
+		return section, nil
+	}
+
+	client, err := globusclient.NewServiceClient(ctx, transport, clientId, clientSecret, []string{transferScope})
+	if err != nil {
+		section.add(checkFail, "service account token", err.Error(), "check GLOBUS_CLIENT_ID and GLOBUS_CLIENT_SECRET of the service account")
+		return section, nil
+	}
+	section.add(checkOk, "service account token", "client "+clientId, "")
+
+	scopes, err := conf.GetGlobusScopes()
+	if err != nil {
+		section.add(checkFail, "configured scopes", err.Error(), "run validate-config")
+		return section, &client
+	}
+	fullClient, err := globusclient.NewServiceClient(ctx, transport, clientId, clientSecret, scopes)
+	if err != nil {
+		section.add(checkFail, "token for all configured scopes", err.Error(), "the checks of the facilities show which scope is missing")
+		return section, &client
+	}
+	granted, err := fullClient.GrantedScopes(ctx)
+	if err != nil {
+		section.add(checkFail, "granted scopes", err.Error(), "")
+		return section, &client
+	}
+	section.add(checkOk, "granted scopes", strings.Join(granted, " "), "")
+	for _, scope := range globusclient.MissingScopes(scopes, granted) {
+		section.add(checkWarn, "scope "+scope, "configured, but not granted to the token", "check the scopes of the service account and the facilities")
+	}
+	return section, &client
+}
+
+// Check that the service account may use the scopes of the facility and list the collection root
+func checkFacility(ctx context.Context, facility config.FacilityConfig, client *globusclient.Client, transport http.RoundTripper) checkSection {
+	section := checkSection{title: fmt.Sprintf("Facility %s (collection %s, direction %s)", facility.Name, facility.Collection, facility.Direction)}
+	if client == nil {
+		section.add(checkFail, "globus", "skipped, no globus token", "")
+		return section
+	}
+
+	single := config.Config{Facilities: []config.FacilityConfig{facility}}
+	scopes, err := single.GetGlobusScopes()
+	if err != nil {
+		section.add(checkFail, "scopes", err.Error(), "run validate-config")
+		return section
+	}
+	consentHint := fmt.Sprintf("grant the service account (%s@clients.auth.globus.org) access to collection %s, and check the scopes of the facility", os.Getenv("GLOBUS_CLIENT_ID"), facility.Collection)
+	consented := true
+	for _, scope := range scopes {
+		granted, err := client.CheckScope(ctx, scope)
+		if err != nil {
+			section.add(checkFail, "consent for "+scope, err.Error(), consentHint)
+			consented = false
+			continue
+		}
+		if missing := globusclient.MissingScopes([]string{scope}, granted); len(missing) > 0 {
+			section.add(checkWarn, "consent for "+scope, "the token doesn't list "+missing[0], consentHint)
+			continue
+		}
+		section.add(checkOk, "consent for "+scope, "", "")
+	}
+	if !consented {
+		section.add(checkFail, "ls /", "skipped, missing consent", "")
+		return section
+	}
+
+	facilityClient, err := globusclient.NewServiceClient(ctx, transport, os.Getenv("GLOBUS_CLIENT_ID"), os.Getenv("GLOBUS_CLIENT_SECRET"), scopes)
+	if err != nil {
+		section.add(checkFail, "ls /", err.Error(), consentHint)
+		return section
+	}
+	entries, err := facilityClient.ListDirectory(ctx, facility.Collection, "/")
+	if err != nil {
+		section.add(checkFail, "ls /", err.Error(), listHint(err, facility))
+		return section
+	}
+	section.add(checkOk, "ls /", fmt.Sprintf("%d entries", len(entries)), "")
+	return section
+}
+
+// Suggest a fix for a failed directory listing
+func listHint(err error, facility config.FacilityConfig) string {
+	var transferErr globusclient.TransferError
+	if !errors.As(err, &transferErr) {
+		return "check the connection to transfer.api.globusonline.org"
+	}
+	switch {
+	case transferErr.Code == "ConsentRequired":
+		return fmt.Sprintf("the scopes of the facility must include the data_access scope of collection %s", facility.Collection)
+	case strings.Contains(transferErr.Code, "NotFound"):
+		return fmt.Sprintf("check that %s is the id of a collection", facility.Collection)
+	case strings.Contains(transferErr.Code, "PermissionDenied"):
+		return fmt.Sprintf("give the service account (%s@clients.auth.globus.org) a role or a read permission on the collection", os.Getenv("GLOBUS_CLIENT_ID"))
+	case strings.HasPrefix(transferErr.Code, "ExternalError"):
+		return "the collection's endpoint is unreachable or not configured correctly, check with its administrator"
+	}
+	return ""
+}
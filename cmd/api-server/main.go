@@ -2,41 +2,141 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/SwissOpenEM/globus"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/api"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/globusclient"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/httpclient"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/logging"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/serviceuser"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tasks"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/util"
 )
 
 // String can be overwritten by using linker flags: -ldflags "-X main.version=VERSION"
 var version string = "DEVELOPMENT_VERSION"
 
-func setupLogging(logLevel string) {
-	level := slog.LevelDebug
-	switch logLevel {
-	case "Info":
-		level = slog.LevelInfo
-	case "Debug":
-		level = slog.LevelDebug
-	case "Error":
-		level = slog.LevelError
-	case "Warning":
-		level = slog.LevelWarn
-	}
-
-	opts := &slog.HandlerOptions{Level: level}
-	h := slog.NewTextHandler(os.Stdout, opts)
+// Level of the default logger, it's changed when the config is reloaded
+var logLevel = new(slog.LevelVar)
+
+func setupLogging(conf config.LogConfig) error {
+	level, err := logging.ParseLevel(conf.Level)
+	if err != nil {
+		return err
+	}
+	logLevel.Set(level)
+	h, err := logging.NewHandler(os.Stdout, conf.Format, logLevel)
+	if err != nil {
+		return err
+	}
 	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// Apply a reloaded config. If any facility is invalid, the old config is kept.
+// Settings other than facilities and task settings require a restart.
+func applyConfig(oldConf config.Config, newConf config.Config, serverHandler *api.ServerHandler, taskPool tasks.TaskPool) bool {
+	facilities, err := api.NewFacilities(newConf.Facilities)
+	if err != nil {
+		slog.Error("invalid facilities in reloaded config, keeping the current config", "error", err)
+		return false
+	}
+	serverHandler.SetFacilities(facilities)
+
+	maxConcurrency := newConf.Task.MaxConcurrency
+	if maxConcurrency == 0 {
+		maxConcurrency = 10
+	}
+	if level, err := logging.ParseLevel(newConf.Log.Level); err == nil {
+		logLevel.Set(level)
+	}
+	if newConf.Log.Format != oldConf.Log.Format {
+		slog.Warn("changing log.format requires a restart")
+	}
+	if !reflect.DeepEqual(newConf.Tracing, oldConf.Tracing) {
+		slog.Warn("changing tracing requires a restart")
+	}
+
+	taskPool.UpdateSettings(maxConcurrency, newConf.Task.PollInterval, *newConf.Task.MinUpdateInterval)
+	if newConf.Task.QueueSize != taskPool.QueueSize() {
+		slog.Warn("changing task.queueSize requires a restart")
+	}
+
+	oldScopes, _ := oldConf.GetGlobusScopes()
+	newScopes, err := newConf.GetGlobusScopes()
+	if err != nil || !slices.Equal(oldScopes, newScopes) {
+		slog.Warn("globus scopes changed, transfers with new collections may fail until the proxy is restarted")
+	}
+	if newConf.ScicatUrl != oldConf.ScicatUrl || newConf.Scicat != oldConf.Scicat || newConf.Outbound != oldConf.Outbound || newConf.TLS != oldConf.TLS || newConf.Auth != oldConf.Auth || newConf.Port != oldConf.Port || newConf.StateDir != oldConf.StateDir {
+		slog.Warn("changing scicatUrl, scicat, outbound, tls, auth, port or stateDir requires a restart")
+	}
+	if !slices.Equal(newConf.AdminGroups, oldConf.AdminGroups) || newConf.Reconcile != oldConf.Reconcile || newConf.ShutdownTimeout != oldConf.ShutdownTimeout {
+		slog.Warn("changing adminGroups, reconcile or shutdownTimeout requires a restart")
+	}
+	slog.Info("Applied reloaded config", "facilities", len(facilities))
+	return true
+}
+
+// Backoff between the attempts to reach SciCat and Globus while starting
+var startupBackoff = util.Backoff{Initial: time.Second, Max: time.Minute}
+
+// Retry a startup step until it succeeds or the context is done. If readiness is
+// not nil, the step is reported to the readiness probe while it's failing.
+func retryStartup[T any](ctx context.Context, readiness *api.Readiness, step string, fn func() (T, error)) (T, error) {
+	var result T
+	if readiness != nil {
+		readiness.SetWaiting(step, nil)
+	}
+	err := util.Retry(ctx, startupBackoff, func() error {
+		var err error
+		result, err = fn()
+		return err
+	}, func(err error, delay time.Duration) {
+		if readiness != nil {
+			readiness.SetWaiting(step, err)
+		}
+		slog.Warn("startup step failed, retrying", "step", step, "error", err, "retryIn", delay, "hint", "run the doctor command for details")
+	})
+	return result, err
 }
 
 func main() {
-	slog.Info("Starting globus service", "Version", version)
+	configFlag := flag.String("config", "", "path of the config file (default: $"+config.ConfigPathEnv+", next to the executable or in the user config directory)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [--config path] [validate-config [--samples path] | doctor]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 
-	setupLogging("Debug")
+	switch flag.Arg(0) {
+	case "":
+	case "validate-config":
+		os.Exit(validateConfig(*configFlag, flag.Args()[1:]))
+	case "doctor":
+		os.Exit(doctor(*configFlag, flag.Args()[1:]))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	_ = setupLogging(config.NewLogConfig())
+	slog.Info("Starting globus service", "Version", version)
 
 	// Read configuration
 	globusClientId := os.Getenv("GLOBUS_CLIENT_ID")
@@ -44,75 +144,264 @@ func main() {
 	scicatServiceUserUsername := os.Getenv("SCICAT_SERVICE_USER_USERNAME")
 	scicatServiceUserPassword := os.Getenv("SCICAT_SERVICE_USER_PASSWORD")
 
-	conf, err := config.ReadConfig()
+	confPath, err := config.FindConfigFile(*configFlag)
+	if err != nil {
+		slog.Error("couldn't find config", "error", err)
+		os.Exit(1)
+	}
+	conf, err := config.ReadConfigFile(confPath)
 	if err != nil {
 		slog.Error("couldn't read config", "error", err)
 		os.Exit(1)
 	}
-
-	// Initialize Service User
-	serviceUser, err := serviceuser.CreateServiceUser(conf.ScicatUrl, scicatServiceUserUsername, scicatServiceUserPassword)
+	if err := setupLogging(conf.Log); err != nil {
+		slog.Error("couldn't set up logging", "error", err)
+		os.Exit(1)
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), conf.Tracing, version)
 	if err != nil {
-		slog.Error("couldn't create service user", "error", err)
+		slog.Error("couldn't set up tracing", "error", err)
 		os.Exit(1)
 	}
 
-	// Initialize Globus user
+	// Configuration errors are fatal, unlike unavailable dependencies
 	globusScopes, err := conf.GetGlobusScopes()
 	if err != nil {
 		slog.Error("error reading configuration", "error", err)
 		os.Exit(1)
 	}
 
-	globusClient, err := globus.AuthCreateServiceClient(context.Background(), globusClientId, globusClientSecret, globusScopes)
+	facilities, err := api.NewFacilities(conf.Facilities)
 	if err != nil {
-		slog.Error("couldn't create globus client", "error", err)
+		slog.Error("unable to configure facilities", "error", err)
 		os.Exit(1)
 	}
 
-	// Initialize task pool
-	maxConcurrency := conf.Task.MaxConcurrency
-	if conf.Task.MaxConcurrency == 0 {
-		maxConcurrency = 10
+	// Connections to SciCat and Globus
+	transport, err := httpclient.NewTransport(conf.Outbound)
+	if err != nil {
+		slog.Error("couldn't set up outbound connections", "error", err)
+		os.Exit(1)
 	}
 
-	taskPool := tasks.CreateTaskPool(conf.ScicatUrl, globusClient, serviceUser, maxConcurrency, conf.Task.QueueSize, conf.Task.PollInterval)
+	var jwtAuth *api.JwtAuthenticator
+	if conf.Auth.Mode == config.AuthModeJwt {
+		jwtAuth, err = api.NewJwtAuthenticator(conf.Auth.Jwt, &http.Client{Transport: transport})
+		if err != nil {
+			slog.Error("couldn't set up JWT authentication", "error", err)
+			os.Exit(1)
+		}
+	}
 
-	err = tasks.RestoreGlobusTransferJobsFromScicat(conf.ScicatUrl, serviceUser, taskPool)
+	// Open the local state store
+	stateDir, err := conf.GetStateDir()
+	if err != nil {
+		slog.Error("couldn't determine state directory", "error", err)
+		os.Exit(1)
+	}
+	stateStore, err := store.Open(filepath.Join(stateDir, "state.db"))
 	if err != nil {
-		slog.Error("couldn't resume unfinished jobs", "error", err)
+		slog.Error("couldn't open state store", "error", err)
 		os.Exit(1)
 	}
+	defer stateStore.Close()
+	if err := stateStore.PruneIdempotencyKeys(24 * time.Hour); err != nil {
+		slog.Warn("couldn't prune idempotency keys", "error", err)
+	}
 
-	facilities := make(map[string]api.Facility, len(conf.Facilities))
-	for _, facConf := range conf.Facilities {
-		if _, exists := facilities[facConf.Name]; exists {
-			slog.Error("duplicate facility. Overwriting previous values", "Name", facConf.Name)
-		}
-		facility, err := api.NewFacility(facConf)
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stopSignals()
+
+	// Serve the health endpoints while waiting for SciCat and Globus, the readiness
+	// probe fails until the proxy accepts requests
+	scicatClient := scicat.NewClient(conf.ScicatUrl, conf.Scicat, transport)
+	readiness := api.NewReadiness()
+	startupHandler := api.NewStartupServerHandler(version, readiness)
+	startupRouter, err := api.NewRouter(&startupHandler, scicatClient)
+	if err != nil {
+		slog.Error("couldn't create server", "error", err)
+		os.Exit(1)
+	}
+	handler := api.NewSwitchableHandler(startupRouter)
+	var serverTLSConfig *tls.Config
+	if conf.TLS.Enabled() {
+		serverTLS, err := api.NewServerTLS(conf.TLS)
 		if err != nil {
-			slog.Error("unable to configure facility", "Name", facConf.Name, "error", err)
+			slog.Error("couldn't set up TLS", "error", err)
 			os.Exit(1)
 		}
-		facilities[facConf.Name] = *facility
+		if err := serverTLS.Watch(signalCtx); err != nil {
+			slog.Warn("couldn't watch TLS certificates, changes require a restart", "error", err)
+		}
+		serverTLSConfig = serverTLS.Config()
+	}
+	server := api.NewServer(handler, conf.Port, serverTLSConfig)
+
+	startupCtx, cancelStartup := context.WithCancel(signalCtx)
+	defer cancelStartup()
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server", "port", conf.Port, "tls", conf.TLS.Enabled(), "clientAuth", conf.TLS.ClientAuth)
+		serverErr <- api.ListenAndServe(server)
+		cancelStartup()
+	}()
+
+	// Initialize Service User
+	serviceUser, err := retryStartup(startupCtx, readiness, "SciCat service user login", func() (serviceuser.ScicatServiceUser, error) {
+		return serviceuser.CreateServiceUser(conf.ScicatUrl, scicatServiceUserUsername, scicatServiceUserPassword, scicatClient.HttpClient())
+	})
+
+	// Initialize Globus client
+	var serviceClient globusclient.Client
+	if err == nil {
+		serviceClient, err = retryStartup(startupCtx, readiness, "Globus client", func() (globusclient.Client, error) {
+			return globusclient.NewServiceClient(context.Background(), transport, globusClientId, globusClientSecret, globusScopes)
+		})
+	}
+
+	// Only fails if the server failed or the proxy is shut down while starting
+	if err != nil {
+		select {
+		case err := <-serverErr:
+			slog.Error("server encountered an error", "error", err)
+			os.Exit(1)
+		default:
+		}
+		slog.Info("Shutting down before the startup completed")
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), time.Duration(conf.ShutdownTimeout)*time.Second)
+		defer cancelShutdown()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("couldn't finish in-flight requests", "error", err)
+		}
+		return
+	}
+	globusClient := serviceClient.GlobusClient
+
+	// Initialize the outbox for SciCat job updates
+	outbox, err := tasks.NewStoreOutbox(stateStore, tasks.ScicatJobUpdateSender(scicatClient, serviceUser))
+	if err != nil {
+		slog.Error("couldn't create outbox for scicat job updates", "error", err)
+		os.Exit(1)
+	}
+	outboxCtx, stopOutbox := context.WithCancel(context.Background())
+	outboxDone := make(chan struct{})
+	go func() {
+		outbox.Run(outboxCtx)
+		close(outboxDone)
+	}()
+
+	// Globus tasks of failed submissions that couldn't be cancelled
+	compensations, err := tasks.NewCompensationQueue(stateStore)
+	if err != nil {
+		slog.Error("couldn't load pending compensations", "error", err)
+		os.Exit(1)
 	}
 
-	serverHandler, err := api.NewServerHandler(version, globusClient, conf.ScicatUrl, serviceUser, &facilities, taskPool)
+	// Initialize task pool
+	maxConcurrency := conf.Task.MaxConcurrency
+	if conf.Task.MaxConcurrency == 0 {
+		maxConcurrency = 10
+	}
+
+	taskPool := tasks.CreateTaskPool(scicatClient, globusClient, serviceUser, outbox, compensations, stateStore, maxConcurrency, conf.Task.QueueSize, conf.Task.PollInterval, *conf.Task.MinUpdateInterval)
+
+	serverHandler, err := api.NewServerHandler(version, readiness, globusClient, scicatClient, serviceUser, &facilities, taskPool, stateStore, conf.AdminGroups, api.NewIdentityCache(conf.Auth.IdentityCache), jwtAuth)
 	if err != nil {
 		slog.Error("couldn't create server handler", "error", err)
 		os.Exit(1)
 	}
 
-	server, err := api.NewServer(&serverHandler, conf.Port, conf.ScicatUrl)
+	router, err := api.NewRouter(&serverHandler, scicatClient)
 	if err != nil {
 		slog.Error("couldn't create server", "error", err)
 		os.Exit(1)
 	}
+	handler.Switch(router)
+	readiness.SetReady()
+	slog.Info("Proxy is ready")
+
+	// Resume unfinished transfers in the background, and reconcile them afterwards
+	reconcileCtx, stopReconciler := context.WithCancel(context.Background())
+	defer stopReconciler()
+	// the reconciler, or the retries of compensations, may still use the task pool and the outbox
+	reconciling := &sync.WaitGroup{}
+	restoreDone := make(chan struct{})
+	go func() {
+		defer close(restoreDone)
+		// transfers resumed by failed attempts stay monitored, keep them in the summary
+		resumed := []tasks.RestoredTransfer{}
+		restoreSummary, err := retryStartup(signalCtx, nil, "restoring unfinished transfers", func() (tasks.RestoreSummary, error) {
+			summary, err := tasks.RestoreTransfers(signalCtx, scicatClient, serviceUser, taskPool)
+			resumed = append(resumed, summary.Resumed...)
+			return summary, err
+		})
+		if err != nil {
+			return
+		}
+		restoreSummary.Resumed = resumed
+		serverHandler.SetRestoreSummary(restoreSummary)
 
-	slog.Info("Starting server", "port", conf.Port)
-	err = server.ListenAndServe()
+		if !conf.Reconcile.Disabled {
+			reconciler := tasks.NewReconciler(scicatClient, serviceUser, globusClient, taskPool, conf.Reconcile)
+			serverHandler.SetReconciler(reconciler)
+			reconciling.Go(func() { reconciler.Run(reconcileCtx) })
+		} else {
+			// the reconciler retries them otherwise
+			reconciling.Go(func() {
+				compensations.Run(reconcileCtx, globusClient, time.Duration(conf.Reconcile.Interval)*time.Second)
+			})
+		}
+	}()
+
+	// Reload facilities and task settings when the config changes
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	appliedConf := conf
+	err = config.Watch(watchCtx, confPath, func(newConf config.Config) bool {
+		if !applyConfig(appliedConf, newConf, &serverHandler, taskPool) {
+			return false
+		}
+		appliedConf = newConf
+		return true
+	})
 	if err != nil {
+		slog.Warn("couldn't watch config file, changes require a restart", "error", err)
+	}
+
+	select {
+	case err := <-serverErr:
 		slog.Error("server encountered an error", "error", err)
 		os.Exit(1)
+	case <-signalCtx.Done():
+		stopSignals() // a second signal terminates immediately
+	}
+
+	// Shut down in order: stop accepting requests, let submissions finish, stop
+	// reconciling and monitoring, and flush the job updates. Unfinished transfers are resumed by the next instance.
+	stopWatching()
+	slog.Info("Shutting down", "timeout", conf.ShutdownTimeout)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), time.Duration(conf.ShutdownTimeout)*time.Second)
+	defer cancelShutdown()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("couldn't finish in-flight requests", "error", err)
+	}
+	if err := taskPool.WaitForSubmissions(shutdownCtx); err != nil {
+		slog.Warn("couldn't finish in-flight submissions", "error", err)
+	}
+	<-restoreDone
+	stopReconciler()
+	reconciling.Wait()
+	if err := taskPool.Stop(shutdownCtx); err != nil {
+		slog.Warn("couldn't stop transfer monitors", "error", err)
+	}
+	stopOutbox()
+	<-outboxDone
+	if err := outbox.Flush(shutdownCtx); err != nil {
+		slog.Warn("couldn't flush SciCat job updates, they are sent by the next instance", "error", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Warn("couldn't export remaining spans", "error", err)
 	}
+	slog.Info("Shutdown complete")
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/api"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/httpclient"
+)
+
+// Check the config without contacting SciCat or Globus. Returns the exit code.
+func validateConfig(configPath string, args []string) int {
+	flags := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	flags.StringVar(&configPath, "config", configPath, "path of the config file")
+	samplesPath := flags.String("samples", "", "yaml file with a list of sample datasets to render the path templates against")
+	_ = flags.Parse(args)
+
+	confPath, err := config.FindConfigFile(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't find config: %v\n", err)
+		return 1
+	}
+	conf, err := config.ReadConfigFile(confPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: invalid config: %v\n", confPath, err)
+		return 1
+	}
+
+	var samples []byte
+	if *samplesPath != "" {
+		samples, err = os.ReadFile(*samplesPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "couldn't read sample datasets: %v\n", err)
+			return 1
+		}
+	}
+	problems, err := api.ValidateConfig(conf, samples)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *samplesPath, err)
+		return 1
+	}
+	// the certificate files are only loaded when the connections are set up
+	if _, err := httpclient.NewTransport(conf.Outbound); err != nil {
+		problems = append(problems, api.ConfigProblem{Message: fmt.Sprintf("outbound: %v", err)})
+	}
+	if conf.Auth.Mode == config.AuthModeJwt && conf.Auth.Jwt.JwksFile != "" {
+		if _, err := api.NewJwtAuthenticator(conf.Auth.Jwt, nil); err != nil {
+			problems = append(problems, api.ConfigProblem{Message: fmt.Sprintf("auth: %v", err)})
+		}
+	}
+	if conf.TLS.Enabled() {
+		if _, err := api.NewServerTLS(conf.TLS); err != nil {
+			problems = append(problems, api.ConfigProblem{Message: fmt.Sprintf("tls: %v", err)})
+		}
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", confPath, problem)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problem(s) found\n", len(problems))
+		return 1
+	}
+	fmt.Printf("%s: config is valid, %d facilities\n", confPath, len(conf.Facilities))
+	return 0
+}
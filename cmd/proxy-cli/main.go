@@ -0,0 +1,135 @@
+// Command line client of the SciCat Globus Proxy
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// String can be overwritten by using linker flags: -ldflags "-X main.version=VERSION"
+var version string = "DEVELOPMENT_VERSION"
+
+const (
+	urlEnv       = "SCICAT_GLOBUS_PROXY_URL"
+	tokenEnv     = "SCICAT_TOKEN"
+	tokenFileEnv = "SCICAT_TOKEN_FILE"
+	defaultUrl   = "http://localhost:8080"
+)
+
+const usage = `Usage: proxy-cli <command> [flags]
+
+Commands:
+  transfer submit   request a transfer
+  transfer status   show the status and progress of a transfer, --wait polls until it is done
+  transfer list     list the transfers visible to you
+  transfer cancel   cancel a transfer
+  transfer delete   cancel a transfer and delete its SciCat job
+  version           print the version
+
+Run 'proxy-cli transfer <command> --help' for the flags of a command.
+`
+
+// Flags shared by all commands that call the proxy
+type commonOptions struct {
+	url       string
+	token     string
+	tokenFile string
+	output    string
+}
+
+func addCommonFlags(flags *flag.FlagSet) *commonOptions {
+	opts := &commonOptions{}
+	defaultProxyUrl := os.Getenv(urlEnv)
+	if defaultProxyUrl == "" {
+		defaultProxyUrl = defaultUrl
+	}
+	flags.StringVar(&opts.url, "url", defaultProxyUrl, "url of the proxy (env "+urlEnv+")")
+	flags.StringVar(&opts.token, "token", "", "SciCat token (env "+tokenEnv+")")
+	flags.StringVar(&opts.tokenFile, "token-file", "", "file containing the SciCat token (env "+tokenFileEnv+")")
+	flags.StringVar(&opts.output, "output", "table", "output format, 'table' or 'json'")
+	return opts
+}
+
+// Get the SciCat token from the flags, the environment or a file, in this order
+func (opts *commonOptions) scicatToken() (string, error) {
+	if opts.token != "" {
+		return opts.token, nil
+	}
+	tokenFile := opts.tokenFile
+	if tokenFile == "" {
+		if token := os.Getenv(tokenEnv); token != "" {
+			return token, nil
+		}
+		tokenFile = os.Getenv(tokenFileEnv)
+	}
+	if tokenFile == "" {
+		return "", fmt.Errorf("no SciCat token given, use --token, --token-file, %s or %s", tokenEnv, tokenFileEnv)
+	}
+	contents, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", tokenFile)
+	}
+	return token, nil
+}
+
+func (opts *commonOptions) validate() error {
+	switch opts.output {
+	case "table", "json":
+		return nil
+	default:
+		return fmt.Errorf("invalid output format '%s', expected 'table' or 'json'", opts.output)
+	}
+}
+
+// Print v as indented json, or call table to print it as a table
+func (opts *commonOptions) print(v any, table func(w *tabwriter.Writer)) error {
+	if opts.output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// Returned for invalid arguments, which exit with status 2
+var errUsage = errors.New("usage error")
+
+func main() {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch args[0] {
+	case "transfer":
+		err = runTransfer(args[1:])
+	case "version":
+		fmt.Println(version)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+
+	if errors.Is(err, errUsage) {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
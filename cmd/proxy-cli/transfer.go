@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/pkg/client"
+)
+
+// Timeout of a single request to the proxy
+const requestTimeout = 2 * time.Minute
+
+// A flag that can be given several times
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func runTransfer(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return errUsage
+	}
+	switch args[0] {
+	case "submit":
+		return submitTransfer(args[1:])
+	case "status":
+		return transferStatus(args[1:])
+	case "list":
+		return listTransfers(args[1:])
+	case "cancel":
+		return cancelTransfer("cancel", args[1:], false)
+	case "delete":
+		return cancelTransfer("delete", args[1:], true)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown transfer command '%s'\n\n%s", args[0], usage)
+		return errUsage
+	}
+}
+
+// Parse the flags of a command and build the client
+func parseCommand(flags *flag.FlagSet, opts *commonOptions, args []string) (*client.ClientWithResponses, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	token, err := opts.scicatToken()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewAuthenticatedClient(opts.url, token)
+}
+
+// Get the job id, the only positional argument of a command
+func jobIdArg(flags *flag.FlagSet) (string, error) {
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "expected the SciCat job id of the transfer\n")
+		flags.Usage()
+		return "", errUsage
+	}
+	return flags.Arg(0), nil
+}
+
+func submitTransfer(args []string) error {
+	flags := flag.NewFlagSet("transfer submit", flag.ContinueOnError)
+	opts := addCommonFlags(flags)
+	source := flags.String("source", "", "name of the source facility (required)")
+	dest := flags.String("dest", "", "name of the destination facility (required)")
+	pid := flags.String("pid", "", "pid of the dataset (required)")
+	rootPath := flags.String("collection-root-path", "", "path of the root of the globus collection on the source facility")
+	noArchive := flags.Bool("no-archive", false, "don't mark the dataset as archivable after the transfer")
+	async := flags.Bool("async", false, "return as soon as the SciCat job is created")
+	idempotencyKey := flags.String("idempotency-key", "", "key identifying the request, so that it can be retried safely")
+	var files stringList
+	flags.Var(&files, "file", "path of a file to transfer, relative to the dataset source folder. Can be repeated, the whole dataset is transferred by default")
+	fileList := flags.String("file-list", "", "json file with a list of {\"path\", \"isSymlink\"} objects to transfer")
+	wait := flags.Bool("wait", false, "wait until the transfer is done and print its final status")
+	interval := flags.Duration("interval", 10*time.Second, "polling interval with --wait")
+
+	proxy, err := parseCommand(flags, opts, args)
+	if err != nil {
+		return err
+	}
+	if *source == "" || *dest == "" || *pid == "" {
+		fmt.Fprintf(os.Stderr, "--source, --dest and --pid are required\n")
+		flags.Usage()
+		return errUsage
+	}
+
+	var filesToTransfer []client.FileToTransfer
+	if *fileList != "" {
+		contents, err := os.ReadFile(*fileList)
+		if err != nil {
+			return fmt.Errorf("couldn't read file list: %w", err)
+		}
+		if err := json.Unmarshal(contents, &filesToTransfer); err != nil {
+			return fmt.Errorf("invalid file list %s: %w", *fileList, err)
+		}
+	}
+	for _, file := range files {
+		filesToTransfer = append(filesToTransfer, client.FileToTransfer{Path: file})
+	}
+
+	params := client.PostTransferTaskParams{
+		SourceFacility:     *source,
+		DestFacility:       *dest,
+		ScicatPid:          *pid,
+		CollectionRootPath: *rootPath,
+	}
+	if *noArchive {
+		autoArchive := false
+		params.AutoArchive = &autoArchive
+	}
+	if *async {
+		params.Async = async
+	}
+	if *idempotencyKey != "" {
+		params.IdempotencyKey = idempotencyKey
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	resp, err := proxy.PostTransferTaskWithResponse(ctx, &params, client.PostTransferTaskJSONRequestBody{FileList: optionalList(filesToTransfer)})
+	if err == nil {
+		err = client.CheckResponse(resp.StatusCode(), resp.Body)
+	}
+	if err != nil {
+		return err
+	}
+	var jobId, statusUrl string
+	var result any
+	if resp.JSON202 != nil {
+		jobId, statusUrl, result = resp.JSON202.JobId, resp.JSON202.StatusUrl, resp.JSON202
+	} else if resp.JSON200 != nil {
+		jobId, result = resp.JSON200.JobId, resp.JSON200
+	} else {
+		return fmt.Errorf("unexpected response: %s", resp.Status())
+	}
+	if *wait {
+		return waitForTransfer(proxy, opts, jobId, *interval)
+	}
+	return opts.print(result, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "JOB ID\t%s\n", jobId)
+		if statusUrl != "" {
+			fmt.Fprintf(w, "STATUS URL\t%s\n", statusUrl)
+		}
+	})
+}
+
+func transferStatus(args []string) error {
+	flags := flag.NewFlagSet("transfer status", flag.ContinueOnError)
+	opts := addCommonFlags(flags)
+	wait := flags.Bool("wait", false, "wait until the transfer is done")
+	interval := flags.Duration("interval", 10*time.Second, "polling interval with --wait")
+	proxy, err := parseCommand(flags, opts, args)
+	if err != nil {
+		return err
+	}
+	jobId, err := jobIdArg(flags)
+	if err != nil {
+		return err
+	}
+
+	if *wait {
+		return waitForTransfer(proxy, opts, jobId, *interval)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	resp, err := proxy.GetTransferTaskWithResponse(ctx, jobId)
+	if err == nil {
+		err = client.CheckResponse(resp.StatusCode(), resp.Body)
+	}
+	if err != nil {
+		return err
+	}
+	if resp.JSON200 == nil {
+		return fmt.Errorf("unexpected response: %s", resp.Status())
+	}
+	return printTransfer(opts, *resp.JSON200)
+}
+
+// Poll a transfer until it's done, print progress to stderr and the final status to stdout
+func waitForTransfer(proxy *client.ClientWithResponses, opts *commonOptions, jobId string, interval time.Duration) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	item, err := client.WaitForTransfer(ctx, proxy, jobId, interval, func(item client.TransferItem) {
+		fmt.Fprintf(os.Stderr, "%s %s: %s, %d/%d files\n", time.Now().Format(time.TimeOnly), jobId, item.Status, value(item.FilesTransferred), value(item.FilesTotal))
+	})
+	if err != nil {
+		return err
+	}
+	if err := printTransfer(opts, item); err != nil {
+		return err
+	}
+	if item.Status != client.Finished {
+		return fmt.Errorf("transfer %s ended with status %s", jobId, item.Status)
+	}
+	return nil
+}
+
+func printTransfer(opts *commonOptions, item client.TransferItem) error {
+	return opts.print(item, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "JOB ID\t%s\n", item.TransferId)
+		fmt.Fprintf(w, "STATUS\t%s\n", item.Status)
+		fmt.Fprintf(w, "MESSAGE\t%s\n", value(item.Message))
+		fmt.Fprintf(w, "GLOBUS TASK\t%s\n", value(item.GlobusTaskId))
+		fmt.Fprintf(w, "DATASETS\t%s\n", strings.Join(value(item.DatasetPids), ", "))
+		fmt.Fprintf(w, "SOURCE\t%s\n", value(item.SourceFacility))
+		fmt.Fprintf(w, "DESTINATION\t%s\n", value(item.DestinationFacility))
+		fmt.Fprintf(w, "FILES\t%d/%d\n", value(item.FilesTransferred), value(item.FilesTotal))
+		fmt.Fprintf(w, "BYTES\t%d\n", value(item.BytesTransferred))
+		fmt.Fprintf(w, "OWNER\t%s (%s)\n", value(item.OwnerUser), value(item.OwnerGroup))
+		fmt.Fprintf(w, "CREATED\t%s\n", formatTime(item.CreatedAt))
+		fmt.Fprintf(w, "UPDATED\t%s\n", formatTime(item.UpdatedAt))
+	})
+}
+
+func listTransfers(args []string) error {
+	flags := flag.NewFlagSet("transfer list", flag.ContinueOnError)
+	opts := addCommonFlags(flags)
+	status := flags.String("status", "", "only list transfers with this status")
+	limit := flags.Int("limit", 100, "maximum number of transfers to list")
+	offset := flags.Int("offset", 0, "number of transfers to skip")
+	proxy, err := parseCommand(flags, opts, args)
+	if err != nil {
+		return err
+	}
+
+	params := client.ListTransfersParams{Limit: limit, Offset: offset}
+	if *status != "" {
+		params.Status = status
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	resp, err := proxy.ListTransfersWithResponse(ctx, &params)
+	if err == nil {
+		err = client.CheckResponse(resp.StatusCode(), resp.Body)
+	}
+	if err != nil {
+		return err
+	}
+	if resp.JSON200 == nil {
+		return fmt.Errorf("unexpected response: %s", resp.Status())
+	}
+	list := resp.JSON200
+	return opts.print(list, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "JOB ID\tSTATUS\tSOURCE\tDESTINATION\tDATASETS\tCREATED")
+		for _, item := range list.Transfers {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", item.TransferId, item.Status, value(item.SourceFacility), value(item.DestinationFacility), strings.Join(value(item.DatasetPids), ","), formatTime(item.CreatedAt))
+		}
+		if len(list.Transfers) < list.Total {
+			fmt.Fprintf(w, "(%d of %d transfers)\n", len(list.Transfers), list.Total)
+		}
+	})
+}
+
+func cancelTransfer(name string, args []string, delete bool) error {
+	flags := flag.NewFlagSet("transfer "+name, flag.ContinueOnError)
+	opts := addCommonFlags(flags)
+	proxy, err := parseCommand(flags, opts, args)
+	if err != nil {
+		return err
+	}
+	jobId, err := jobIdArg(flags)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	resp, err := proxy.DeleteTransferTaskWithResponse(ctx, jobId, &client.DeleteTransferTaskParams{Delete: &delete})
+	if err == nil {
+		err = client.CheckResponse(resp.StatusCode(), resp.Body)
+	}
+	if err != nil {
+		return err
+	}
+	action := "cancelled"
+	if delete {
+		action = "deleted"
+	}
+	result := map[string]string{"jobId": jobId, "result": action}
+	return opts.print(result, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "%s %s\n", action, jobId)
+	})
+}
+
+// Omit an empty list from a request
+func optionalList[T any](list []T) *[]T {
+	if len(list) == 0 {
+		return nil
+	}
+	return &list
+}
+
+// The value of an optional field, or its zero value
+func value[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tasks"
+	"github.com/gin-gonic/gin"
+)
+
+// Get the SciCat user cached in the context by the auth middleware
+func getScicatUser(ctx context.Context) (scicat.User, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return scicat.User{}, fmt.Errorf("context error")
+	}
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return scicat.User{}, fmt.Errorf("no user was found")
+	}
+	scicatUser, ok := u.(scicat.User)
+	if !ok {
+		return scicat.User{}, fmt.Errorf("invalid user in context, type found: '%s'", reflect.TypeOf(u))
+	}
+	return scicatUser, nil
+}
+
+// Check whether the user is member of one of the configured admin groups
+func (s ServerHandler) isAdmin(user scicat.User) bool {
+	for _, group := range s.adminGroups {
+		if slices.Contains(user.Profile.AccessGroups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Record the outcome of restoring transfers for the admin endpoint
+func (s ServerHandler) SetRestoreSummary(summary tasks.RestoreSummary) {
+	s.restoreSummary.Store(&summary)
+}
+
+func (s ServerHandler) GetRestoreSummary(ctx context.Context, request GetRestoreSummaryRequestObject) (GetRestoreSummaryResponseObject, error) {
+	user, err := getScicatUser(ctx)
+	if err != nil {
+		return GetRestoreSummary500JSONResponse{
+			Message: getPointerOrNil(err.Error()),
+		}, nil
+	}
+	if !s.isAdmin(user) {
+		return GetRestoreSummary403JSONResponse{
+			Message: getPointerOrNil("you need to be an administrator to access this endpoint"),
+		}, nil
+	}
+
+	summary := s.restoreSummary.Load()
+	if summary == nil {
+		return GetRestoreSummary503JSONResponse{
+			Message: getPointerOrNil("transfers were not restored yet"),
+		}, nil
+	}
+
+	response := GetRestoreSummary200JSONResponse{
+		Time:    summary.Time,
+		Resumed: make([]RestoredTransfer, len(summary.Resumed)),
+		Skipped: make([]SkippedTransfer, len(summary.Skipped)),
+		Error:   getPointerOrNil(summary.Error),
+	}
+	for i, r := range summary.Resumed {
+		response.Resumed[i] = RestoredTransfer{
+			ScicatJobId:  r.ScicatJobId,
+			GlobusTaskId: r.GlobusTaskId,
+			Source:       RestoredTransferSource(r.Source),
+		}
+	}
+	for i, r := range summary.Skipped {
+		response.Skipped[i] = SkippedTransfer{
+			ScicatJobId: r.ScicatJobId,
+			Reason:      r.Reason,
+		}
+	}
+	return response, nil
+}
+
+// Expose the reports of the reconciler on the admin endpoint
+func (s ServerHandler) SetReconciler(reconciler *tasks.Reconciler) {
+	s.reconciler.Store(reconciler)
+}
+
+func (s ServerHandler) GetReconcileReport(ctx context.Context, request GetReconcileReportRequestObject) (GetReconcileReportResponseObject, error) {
+	user, err := getScicatUser(ctx)
+	if err != nil {
+		return GetReconcileReport500JSONResponse{
+			Message: getPointerOrNil(err.Error()),
+		}, nil
+	}
+	if !s.isAdmin(user) {
+		return GetReconcileReport403JSONResponse{
+			Message: getPointerOrNil("you need to be an administrator to access this endpoint"),
+		}, nil
+	}
+
+	reconciler := s.reconciler.Load()
+	if reconciler == nil {
+		return GetReconcileReport503JSONResponse{
+			Message: getPointerOrNil("reconciliation is disabled"),
+		}, nil
+	}
+	report := reconciler.LastReport()
+	if report == nil {
+		return GetReconcileReport503JSONResponse{
+			Message: getPointerOrNil("reconciliation did not run yet"),
+		}, nil
+	}
+
+	response := GetReconcileReport200JSONResponse{
+		Time:          report.Time,
+		Reattached:    report.Reattached,
+		Resynced:      report.Resynced,
+		OrphanedTasks: make([]OrphanedTask, len(report.OrphanedTasks)),
+		OrphanedJobs:  make([]OrphanedJob, len(report.OrphanedJobs)),
+		Interrupted:   report.Interrupted,
+		Compensations: make([]Compensation, len(report.Compensations)),
+		Error:         getPointerOrNil(report.Error),
+	}
+	for i, o := range report.OrphanedTasks {
+		response.OrphanedTasks[i] = OrphanedTask{
+			GlobusTaskId: o.GlobusTaskId,
+			Label:        o.Label,
+			Status:       o.Status,
+			Action:       OrphanedTaskAction(o.Action),
+			Error:        getPointerOrNil(o.Error),
+		}
+	}
+	for i, o := range report.OrphanedJobs {
+		response.OrphanedJobs[i] = OrphanedJob{
+			ScicatJobId: o.ScicatJobId,
+			Reason:      o.Reason,
+			Action:      OrphanedJobAction(o.Action),
+		}
+	}
+	for i, c := range report.Compensations {
+		response.Compensations[i] = Compensation{
+			GlobusTaskId: c.GlobusTaskId,
+			ScicatJobId:  c.ScicatJobId,
+			Attempts:     c.Attempts,
+			Error:        getPointerOrNil(c.LastError),
+		}
+	}
+	return response, nil
+}
+
+func (s ServerHandler) FlushIdentityCache(ctx context.Context, request FlushIdentityCacheRequestObject) (FlushIdentityCacheResponseObject, error) {
+	user, err := getScicatUser(ctx)
+	if err != nil {
+		return FlushIdentityCache500JSONResponse{
+			Message: getPointerOrNil(err.Error()),
+		}, nil
+	}
+	if !s.isAdmin(user) {
+		return FlushIdentityCache403JSONResponse{
+			Message: getPointerOrNil("you need to be an administrator to access this endpoint"),
+		}, nil
+	}
+
+	if s.identityCache == nil {
+		return FlushIdentityCache503JSONResponse{
+			Message: getPointerOrNil("the identity cache is disabled"),
+		}, nil
+	}
+	flushed := s.identityCache.Flush()
+	slog.InfoContext(ctx, "Flushed identity cache", "entries", flushed, "admin", user.Profile.Username)
+	return FlushIdentityCache200JSONResponse{Flushed: flushed}, nil
+}
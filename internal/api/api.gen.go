@@ -16,6 +16,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
@@ -27,6 +28,107 @@ const (
 	ScicatKeyAuthScopes = "ScicatKeyAuth.Scopes"
 )
 
+// Defines values for OrphanedJobAction.
+const (
+	OrphanedJobActionFail   OrphanedJobAction = "fail"
+	OrphanedJobActionReport OrphanedJobAction = "report"
+)
+
+// Valid indicates whether the value is a known member of the OrphanedJobAction enum.
+func (e OrphanedJobAction) Valid() bool {
+	switch e {
+	case OrphanedJobActionFail:
+		return true
+	case OrphanedJobActionReport:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for OrphanedTaskAction.
+const (
+	OrphanedTaskActionCancel OrphanedTaskAction = "cancel"
+	OrphanedTaskActionReport OrphanedTaskAction = "report"
+)
+
+// Valid indicates whether the value is a known member of the OrphanedTaskAction enum.
+func (e OrphanedTaskAction) Valid() bool {
+	switch e {
+	case OrphanedTaskActionCancel:
+		return true
+	case OrphanedTaskActionReport:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for RestoredTransferSource.
+const (
+	Scicat RestoredTransferSource = "scicat"
+	Store  RestoredTransferSource = "store"
+)
+
+// Valid indicates whether the value is a known member of the RestoredTransferSource enum.
+func (e RestoredTransferSource) Valid() bool {
+	switch e {
+	case Scicat:
+		return true
+	case Store:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for TransferItemStatus.
+const (
+	Cancelled        TransferItemStatus = "cancelled"
+	Failed           TransferItemStatus = "failed"
+	Finished         TransferItemStatus = "finished"
+	InvalidStatus    TransferItemStatus = "invalid status"
+	SubmissionFailed TransferItemStatus = "submission_failed"
+	Submitting       TransferItemStatus = "submitting"
+	Transferring     TransferItemStatus = "transferring"
+	Waiting          TransferItemStatus = "waiting"
+)
+
+// Valid indicates whether the value is a known member of the TransferItemStatus enum.
+func (e TransferItemStatus) Valid() bool {
+	switch e {
+	case Cancelled:
+		return true
+	case Failed:
+		return true
+	case Finished:
+		return true
+	case InvalidStatus:
+		return true
+	case SubmissionFailed:
+		return true
+	case Submitting:
+		return true
+	case Transferring:
+		return true
+	case Waiting:
+		return true
+	default:
+		return false
+	}
+}
+
+// Compensation cancellation of the globus task of a failed submission
+type Compensation struct {
+	// Attempts number of cancellation attempts so far
+	Attempts int `json:"attempts"`
+
+	// Error set if the cancellation failed again
+	Error        *string `json:"error,omitempty"`
+	GlobusTaskId string  `json:"globusTaskId"`
+	ScicatJobId  string  `json:"scicatJobId"`
+}
+
 // FileToTransfer the file to transfer as part of a transfer request
 type FileToTransfer struct {
 	// IsSymlink specifies whether this file is a symlink
@@ -36,6 +138,148 @@ type FileToTransfer struct {
 	Path string `json:"path"`
 }
 
+// HealthStatus the state of the proxy
+type HealthStatus struct {
+	// LastError the last error connecting to the dependency it's waiting for
+	LastError *string `json:"lastError,omitempty"`
+
+	// Ready whether the proxy accepts requests
+	Ready bool `json:"ready"`
+
+	// WaitingFor the dependency the proxy is waiting for while starting, eg. "SciCat service user" or "Globus client"
+	WaitingFor *string `json:"waitingFor,omitempty"`
+}
+
+// IdentityCacheFlush outcome of flushing the identity cache
+type IdentityCacheFlush struct {
+	// Flushed number of removed cache entries, including rejected tokens
+	Flushed int `json:"flushed"`
+}
+
+// OrphanedJob an unfinished SciCat job without a globus task
+type OrphanedJob struct {
+	// Action what was done with the job
+	Action      OrphanedJobAction `json:"action"`
+	Reason      string            `json:"reason"`
+	ScicatJobId string            `json:"scicatJobId"`
+}
+
+// OrphanedJobAction what was done with the job
+type OrphanedJobAction string
+
+// OrphanedTask an active globus task that belongs to no SciCat job
+type OrphanedTask struct {
+	// Action what was done with the task
+	Action OrphanedTaskAction `json:"action"`
+
+	// Error set if the action failed
+	Error        *string `json:"error,omitempty"`
+	GlobusTaskId string  `json:"globusTaskId"`
+	Label        string  `json:"label"`
+	Status       string  `json:"status"`
+}
+
+// OrphanedTaskAction what was done with the task
+type OrphanedTaskAction string
+
+// ReconcileReport outcome of one reconciliation run
+type ReconcileReport struct {
+	// Compensations retried cancellations of globus tasks whose submission failed
+	Compensations []Compensation `json:"compensations"`
+
+	// Error set if the reconciliation was aborted
+	Error *string `json:"error,omitempty"`
+
+	// Interrupted ids of jobs whose submission was interrupted before a globus task was attached
+	Interrupted   []string       `json:"interrupted"`
+	OrphanedJobs  []OrphanedJob  `json:"orphanedJobs"`
+	OrphanedTasks []OrphanedTask `json:"orphanedTasks"`
+
+	// Reattached ids of jobs whose transfer was not monitored and got a new monitor
+	Reattached []string `json:"reattached"`
+
+	// Resynced ids of jobs still transferring in SciCat although the transfer is done
+	Resynced []string `json:"resynced"`
+
+	// Time when the reconciliation ran
+	Time time.Time `json:"time"`
+}
+
+// RestoreSummary outcome of resuming unfinished transfers at startup
+type RestoreSummary struct {
+	// Error set if restoring was aborted
+	Error   *string            `json:"error,omitempty"`
+	Resumed []RestoredTransfer `json:"resumed"`
+	Skipped []SkippedTransfer  `json:"skipped"`
+
+	// Time when the transfers were restored
+	Time time.Time `json:"time"`
+}
+
+// RestoredTransfer a transfer that is monitored again
+type RestoredTransfer struct {
+	GlobusTaskId string `json:"globusTaskId"`
+	ScicatJobId  string `json:"scicatJobId"`
+
+	// Source where the transfer was restored from
+	Source RestoredTransferSource `json:"source"`
+}
+
+// RestoredTransferSource where the transfer was restored from
+type RestoredTransferSource string
+
+// SkippedTransfer an unfinished transfer that could not be resumed
+type SkippedTransfer struct {
+	Reason      string `json:"reason"`
+	ScicatJobId string `json:"scicatJobId"`
+}
+
+// TransferAccepted a transfer that is being submitted to globus in the background
+type TransferAccepted struct {
+	// JobId the SciCat job id of the transfer job
+	JobId string `json:"jobId"`
+
+	// StatusUrl path of the status of the transfer at the proxy
+	StatusUrl string `json:"statusUrl"`
+}
+
+// TransferItem defines model for TransferItem.
+type TransferItem struct {
+	BytesTotal          *int               `json:"bytesTotal,omitempty"`
+	BytesTransferred    *int               `json:"bytesTransferred,omitempty"`
+	CreatedAt           *time.Time         `json:"createdAt,omitempty"`
+	DatasetPids         *[]string          `json:"datasetPids,omitempty"`
+	DestinationFacility *string            `json:"destinationFacility,omitempty"`
+	FilesTotal          *int               `json:"filesTotal,omitempty"`
+	FilesTransferred    *int               `json:"filesTransferred,omitempty"`
+	GlobusTaskId        *string            `json:"globusTaskId,omitempty"`
+	Message             *string            `json:"message,omitempty"`
+	OwnerGroup          *string            `json:"ownerGroup,omitempty"`
+	OwnerUser           *string            `json:"ownerUser,omitempty"`
+	SourceFacility      *string            `json:"sourceFacility,omitempty"`
+	Status              TransferItemStatus `json:"status"`
+
+	// TransferId the SciCat job id of the transfer
+	TransferId string     `json:"transferId"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+}
+
+// TransferItemStatus defines model for TransferItem.Status.
+type TransferItemStatus string
+
+// TransferList a page of transfers
+type TransferList struct {
+	// Total number of transfers matching the filter
+	Total     int            `json:"total"`
+	Transfers []TransferItem `json:"transfers"`
+}
+
+// TransferStarted a transfer that was submitted to globus
+type TransferStarted struct {
+	// JobId the SciCat job id of the transfer job
+	JobId string `json:"jobId"`
+}
+
 // GeneralErrorResponse defines model for GeneralErrorResponse.
 type GeneralErrorResponse struct {
 	// Details further details, debugging information
@@ -45,6 +289,18 @@ type GeneralErrorResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
+// ListTransfersParams defines parameters for ListTransfers.
+type ListTransfersParams struct {
+	// Status only list transfers with this status
+	Status *string `form:"status,omitempty" json:"status,omitempty"`
+
+	// Limit maximum number of transfers to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset number of transfers to skip
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
 // PostTransferTaskJSONBody defines parameters for PostTransferTask.
 type PostTransferTaskJSONBody struct {
 	FileList *[]FileToTransfer `json:"fileList,omitempty"`
@@ -66,6 +322,12 @@ type PostTransferTaskParams struct {
 
 	// AutoArchive start archive job after successful transfer
 	AutoArchive *bool `form:"autoArchive,omitempty" json:"autoArchive,omitempty"`
+
+	// Async return as soon as the SciCat job is created, and submit the transfer to globus in the background. Failures are reported on the job with the status submission_failed
+	Async *bool `form:"async,omitempty" json:"async,omitempty"`
+
+	// IdempotencyKey unique key identifying this request. Retrying a request with the same key returns the job of the earlier request instead of starting a new transfer
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
 }
 
 // DeleteTransferTaskParams defines parameters for DeleteTransferTask.
@@ -79,12 +341,33 @@ type PostTransferTaskJSONRequestBody PostTransferTaskJSONBody
 
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// flush the identity cache
+	// (DELETE /admin/identity-cache)
+	FlushIdentityCache(c *gin.Context)
+	// get the report of the last reconciliation
+	// (GET /admin/reconcile)
+	GetReconcileReport(c *gin.Context)
+	// get the summary of restored transfers
+	// (GET /admin/restore)
+	GetRestoreSummary(c *gin.Context)
+	// liveness probe
+	// (GET /health/live)
+	GetLiveness(c *gin.Context)
+	// readiness probe
+	// (GET /health/ready)
+	GetReadiness(c *gin.Context)
+	// list transfers
+	// (GET /transfer)
+	ListTransfers(c *gin.Context, params ListTransfersParams)
 	// request a transfer task
 	// (POST /transfer)
 	PostTransferTask(c *gin.Context, params PostTransferTaskParams)
 	// cancels and/or deletes transfer entry
 	// (DELETE /transfer/{scicatJobId})
 	DeleteTransferTask(c *gin.Context, scicatJobId string, params DeleteTransferTaskParams)
+	// get the status of a transfer
+	// (GET /transfer/{scicatJobId})
+	GetTransferTask(c *gin.Context, scicatJobId string)
 	// get SciCat Globus Proxy version
 	// (GET /version)
 	GetVersion(c *gin.Context)
@@ -99,6 +382,121 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(c *gin.Context)
 
+// FlushIdentityCache operation middleware
+func (siw *ServerInterfaceWrapper) FlushIdentityCache(c *gin.Context) {
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.FlushIdentityCache(c)
+}
+
+// GetReconcileReport operation middleware
+func (siw *ServerInterfaceWrapper) GetReconcileReport(c *gin.Context) {
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetReconcileReport(c)
+}
+
+// GetRestoreSummary operation middleware
+func (siw *ServerInterfaceWrapper) GetRestoreSummary(c *gin.Context) {
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetRestoreSummary(c)
+}
+
+// GetLiveness operation middleware
+func (siw *ServerInterfaceWrapper) GetLiveness(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetLiveness(c)
+}
+
+// GetReadiness operation middleware
+func (siw *ServerInterfaceWrapper) GetReadiness(c *gin.Context) {
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetReadiness(c)
+}
+
+// ListTransfers operation middleware
+func (siw *ServerInterfaceWrapper) ListTransfers(c *gin.Context) {
+
+	var err error
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTransfersParams
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "status", c.Request.URL.Query(), &params.Status, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter status: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "limit", c.Request.URL.Query(), &params.Limit, runtime.BindQueryParameterOptions{Type: "integer", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "offset", c.Request.URL.Query(), &params.Offset, runtime.BindQueryParameterOptions{Type: "integer", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter offset: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ListTransfers(c, params)
+}
+
 // PostTransferTask operation middleware
 func (siw *ServerInterfaceWrapper) PostTransferTask(c *gin.Context) {
 
@@ -177,6 +575,35 @@ func (siw *ServerInterfaceWrapper) PostTransferTask(c *gin.Context) {
 		return
 	}
 
+	// ------------- Optional query parameter "async" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", true, false, "async", c.Request.URL.Query(), &params.Async, runtime.BindQueryParameterOptions{Type: "boolean", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter async: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for Idempotency-Key, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false, Type: "string", Format: ""})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter Idempotency-Key: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -224,6 +651,32 @@ func (siw *ServerInterfaceWrapper) DeleteTransferTask(c *gin.Context) {
 	siw.Handler.DeleteTransferTask(c, scicatJobId, params)
 }
 
+// GetTransferTask operation middleware
+func (siw *ServerInterfaceWrapper) GetTransferTask(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "scicatJobId" -------------
+	var scicatJobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "scicatJobId", c.Param("scicatJobId"), &scicatJobId, runtime.BindStyledParameterOptions{Explode: false, Required: true, Type: "string", Format: ""})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scicatJobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTransferTask(c, scicatJobId)
+}
+
 // GetVersion operation middleware
 func (siw *ServerInterfaceWrapper) GetVersion(c *gin.Context) {
 
@@ -264,8 +717,15 @@ func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options
 		ErrorHandler:       errorHandler,
 	}
 
+	router.DELETE(options.BaseURL+"/admin/identity-cache", wrapper.FlushIdentityCache)
+	router.GET(options.BaseURL+"/admin/reconcile", wrapper.GetReconcileReport)
+	router.GET(options.BaseURL+"/admin/restore", wrapper.GetRestoreSummary)
+	router.GET(options.BaseURL+"/health/live", wrapper.GetLiveness)
+	router.GET(options.BaseURL+"/health/ready", wrapper.GetReadiness)
+	router.GET(options.BaseURL+"/transfer", wrapper.ListTransfers)
 	router.POST(options.BaseURL+"/transfer", wrapper.PostTransferTask)
 	router.DELETE(options.BaseURL+"/transfer/:scicatJobId", wrapper.DeleteTransferTask)
+	router.GET(options.BaseURL+"/transfer/:scicatJobId", wrapper.GetTransferTask)
 	router.GET(options.BaseURL+"/version", wrapper.GetVersion)
 }
 
@@ -277,54 +737,34 @@ type GeneralErrorResponseJSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-type PostTransferTaskRequestObject struct {
-	Params PostTransferTaskParams
-	Body   *PostTransferTaskJSONRequestBody
+type FlushIdentityCacheRequestObject struct {
 }
 
-type PostTransferTaskResponseObject interface {
-	VisitPostTransferTaskResponse(w http.ResponseWriter) error
+type FlushIdentityCacheResponseObject interface {
+	VisitFlushIdentityCacheResponse(w http.ResponseWriter) error
 }
 
-type PostTransferTask200JSONResponse struct {
-	// JobId the SciCat job id of the transfer job
-	JobId string `json:"jobId"`
-}
+type FlushIdentityCache200JSONResponse IdentityCacheFlush
 
-func (response PostTransferTask200JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+func (response FlushIdentityCache200JSONResponse) VisitFlushIdentityCacheResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type PostTransferTask400JSONResponse struct {
+type FlushIdentityCache401JSONResponse struct {
 	GeneralErrorResponseJSONResponse
 }
 
-func (response PostTransferTask400JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(400)
-
-	return json.NewEncoder(w).Encode(response)
-}
-
-type PostTransferTask401JSONResponse struct {
-	// Details further details, debugging information
-	Details *string `json:"details,omitempty"`
-
-	// Message the error message
-	Message *string `json:"message,omitempty"`
-}
-
-func (response PostTransferTask401JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+func (response FlushIdentityCache401JSONResponse) VisitFlushIdentityCacheResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(401)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type PostTransferTask403JSONResponse struct {
+type FlushIdentityCache403JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -332,14 +772,14 @@ type PostTransferTask403JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response PostTransferTask403JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+func (response FlushIdentityCache403JSONResponse) VisitFlushIdentityCacheResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(403)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type PostTransferTask500JSONResponse struct {
+type FlushIdentityCache500JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -347,14 +787,14 @@ type PostTransferTask500JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response PostTransferTask500JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+func (response FlushIdentityCache500JSONResponse) VisitFlushIdentityCacheResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(500)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type PostTransferTask503JSONResponse struct {
+type FlushIdentityCache503JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -362,42 +802,41 @@ type PostTransferTask503JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response PostTransferTask503JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+func (response FlushIdentityCache503JSONResponse) VisitFlushIdentityCacheResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(503)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type DeleteTransferTaskRequestObject struct {
-	ScicatJobId string `json:"scicatJobId"`
-	Params      DeleteTransferTaskParams
+type GetReconcileReportRequestObject struct {
 }
 
-type DeleteTransferTaskResponseObject interface {
-	VisitDeleteTransferTaskResponse(w http.ResponseWriter) error
+type GetReconcileReportResponseObject interface {
+	VisitGetReconcileReportResponse(w http.ResponseWriter) error
 }
 
-type DeleteTransferTask200Response struct {
-}
+type GetReconcileReport200JSONResponse ReconcileReport
 
-func (response DeleteTransferTask200Response) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+func (response GetReconcileReport200JSONResponse) VisitGetReconcileReportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
-	return nil
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-type DeleteTransferTask400JSONResponse struct {
+type GetReconcileReport401JSONResponse struct {
 	GeneralErrorResponseJSONResponse
 }
 
-func (response DeleteTransferTask400JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+func (response GetReconcileReport401JSONResponse) VisitGetReconcileReportResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(400)
+	w.WriteHeader(401)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type DeleteTransferTask401JSONResponse struct {
+type GetReconcileReport403JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -405,14 +844,14 @@ type DeleteTransferTask401JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response DeleteTransferTask401JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+func (response GetReconcileReport403JSONResponse) VisitGetReconcileReportResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(401)
+	w.WriteHeader(403)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type DeleteTransferTask403JSONResponse struct {
+type GetReconcileReport500JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -420,7 +859,349 @@ type DeleteTransferTask403JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response DeleteTransferTask403JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+func (response GetReconcileReport500JSONResponse) VisitGetReconcileReportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetReconcileReport503JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetReconcileReport503JSONResponse) VisitGetReconcileReportResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRestoreSummaryRequestObject struct {
+}
+
+type GetRestoreSummaryResponseObject interface {
+	VisitGetRestoreSummaryResponse(w http.ResponseWriter) error
+}
+
+type GetRestoreSummary200JSONResponse RestoreSummary
+
+func (response GetRestoreSummary200JSONResponse) VisitGetRestoreSummaryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRestoreSummary401JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response GetRestoreSummary401JSONResponse) VisitGetRestoreSummaryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRestoreSummary403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetRestoreSummary403JSONResponse) VisitGetRestoreSummaryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRestoreSummary500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetRestoreSummary500JSONResponse) VisitGetRestoreSummaryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetRestoreSummary503JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetRestoreSummary503JSONResponse) VisitGetRestoreSummaryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetLivenessRequestObject struct {
+}
+
+type GetLivenessResponseObject interface {
+	VisitGetLivenessResponse(w http.ResponseWriter) error
+}
+
+type GetLiveness200JSONResponse HealthStatus
+
+func (response GetLiveness200JSONResponse) VisitGetLivenessResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetReadinessRequestObject struct {
+}
+
+type GetReadinessResponseObject interface {
+	VisitGetReadinessResponse(w http.ResponseWriter) error
+}
+
+type GetReadiness200JSONResponse HealthStatus
+
+func (response GetReadiness200JSONResponse) VisitGetReadinessResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetReadiness503JSONResponse HealthStatus
+
+func (response GetReadiness503JSONResponse) VisitGetReadinessResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListTransfersRequestObject struct {
+	Params ListTransfersParams
+}
+
+type ListTransfersResponseObject interface {
+	VisitListTransfersResponse(w http.ResponseWriter) error
+}
+
+type ListTransfers200JSONResponse TransferList
+
+func (response ListTransfers200JSONResponse) VisitListTransfersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListTransfers401JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response ListTransfers401JSONResponse) VisitListTransfersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ListTransfers500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response ListTransfers500JSONResponse) VisitListTransfersResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTaskRequestObject struct {
+	Params PostTransferTaskParams
+	Body   *PostTransferTaskJSONRequestBody
+}
+
+type PostTransferTaskResponseObject interface {
+	VisitPostTransferTaskResponse(w http.ResponseWriter) error
+}
+
+type PostTransferTask200JSONResponse TransferStarted
+
+func (response PostTransferTask200JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask202JSONResponse TransferAccepted
+
+func (response PostTransferTask202JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(202)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response PostTransferTask400JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask401JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostTransferTask401JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostTransferTask403JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask409JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostTransferTask409JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostTransferTask500JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask503JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostTransferTask503JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteTransferTaskRequestObject struct {
+	ScicatJobId string `json:"scicatJobId"`
+	Params      DeleteTransferTaskParams
+}
+
+type DeleteTransferTaskResponseObject interface {
+	VisitDeleteTransferTaskResponse(w http.ResponseWriter) error
+}
+
+type DeleteTransferTask200Response struct {
+}
+
+func (response DeleteTransferTask200Response) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+	w.WriteHeader(200)
+	return nil
+}
+
+type DeleteTransferTask400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response DeleteTransferTask400JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteTransferTask401JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response DeleteTransferTask401JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteTransferTask403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response DeleteTransferTask403JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(403)
 
@@ -442,6 +1223,94 @@ func (response DeleteTransferTask500JSONResponse) VisitDeleteTransferTaskRespons
 	return json.NewEncoder(w).Encode(response)
 }
 
+type GetTransferTaskRequestObject struct {
+	ScicatJobId string `json:"scicatJobId"`
+}
+
+type GetTransferTaskResponseObject interface {
+	VisitGetTransferTaskResponse(w http.ResponseWriter) error
+}
+
+type GetTransferTask200JSONResponse TransferItem
+
+func (response GetTransferTask200JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTask400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response GetTransferTask400JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTask401JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferTask401JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTask403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferTask403JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTask404JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferTask404JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(404)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTask500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferTask500JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
 type GetVersionRequestObject struct {
 }
 
@@ -463,12 +1332,33 @@ func (response GetVersion200JSONResponse) VisitGetVersionResponse(w http.Respons
 
 // StrictServerInterface represents all server handlers.
 type StrictServerInterface interface {
+	// flush the identity cache
+	// (DELETE /admin/identity-cache)
+	FlushIdentityCache(ctx context.Context, request FlushIdentityCacheRequestObject) (FlushIdentityCacheResponseObject, error)
+	// get the report of the last reconciliation
+	// (GET /admin/reconcile)
+	GetReconcileReport(ctx context.Context, request GetReconcileReportRequestObject) (GetReconcileReportResponseObject, error)
+	// get the summary of restored transfers
+	// (GET /admin/restore)
+	GetRestoreSummary(ctx context.Context, request GetRestoreSummaryRequestObject) (GetRestoreSummaryResponseObject, error)
+	// liveness probe
+	// (GET /health/live)
+	GetLiveness(ctx context.Context, request GetLivenessRequestObject) (GetLivenessResponseObject, error)
+	// readiness probe
+	// (GET /health/ready)
+	GetReadiness(ctx context.Context, request GetReadinessRequestObject) (GetReadinessResponseObject, error)
+	// list transfers
+	// (GET /transfer)
+	ListTransfers(ctx context.Context, request ListTransfersRequestObject) (ListTransfersResponseObject, error)
 	// request a transfer task
 	// (POST /transfer)
 	PostTransferTask(ctx context.Context, request PostTransferTaskRequestObject) (PostTransferTaskResponseObject, error)
 	// cancels and/or deletes transfer entry
 	// (DELETE /transfer/{scicatJobId})
 	DeleteTransferTask(ctx context.Context, request DeleteTransferTaskRequestObject) (DeleteTransferTaskResponseObject, error)
+	// get the status of a transfer
+	// (GET /transfer/{scicatJobId})
+	GetTransferTask(ctx context.Context, request GetTransferTaskRequestObject) (GetTransferTaskResponseObject, error)
 	// get SciCat Globus Proxy version
 	// (GET /version)
 	GetVersion(ctx context.Context, request GetVersionRequestObject) (GetVersionResponseObject, error)
@@ -486,6 +1376,158 @@ type strictHandler struct {
 	middlewares []StrictMiddlewareFunc
 }
 
+// FlushIdentityCache operation middleware
+func (sh *strictHandler) FlushIdentityCache(ctx *gin.Context) {
+	var request FlushIdentityCacheRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.FlushIdentityCache(ctx, request.(FlushIdentityCacheRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "FlushIdentityCache")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(FlushIdentityCacheResponseObject); ok {
+		if err := validResponse.VisitFlushIdentityCacheResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetReconcileReport operation middleware
+func (sh *strictHandler) GetReconcileReport(ctx *gin.Context) {
+	var request GetReconcileReportRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetReconcileReport(ctx, request.(GetReconcileReportRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetReconcileReport")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetReconcileReportResponseObject); ok {
+		if err := validResponse.VisitGetReconcileReportResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetRestoreSummary operation middleware
+func (sh *strictHandler) GetRestoreSummary(ctx *gin.Context) {
+	var request GetRestoreSummaryRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetRestoreSummary(ctx, request.(GetRestoreSummaryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetRestoreSummary")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetRestoreSummaryResponseObject); ok {
+		if err := validResponse.VisitGetRestoreSummaryResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetLiveness operation middleware
+func (sh *strictHandler) GetLiveness(ctx *gin.Context) {
+	var request GetLivenessRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetLiveness(ctx, request.(GetLivenessRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetLiveness")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetLivenessResponseObject); ok {
+		if err := validResponse.VisitGetLivenessResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetReadiness operation middleware
+func (sh *strictHandler) GetReadiness(ctx *gin.Context) {
+	var request GetReadinessRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetReadiness(ctx, request.(GetReadinessRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetReadiness")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetReadinessResponseObject); ok {
+		if err := validResponse.VisitGetReadinessResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ListTransfers operation middleware
+func (sh *strictHandler) ListTransfers(ctx *gin.Context, params ListTransfersParams) {
+	var request ListTransfersRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ListTransfers(ctx, request.(ListTransfersRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListTransfers")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(ListTransfersResponseObject); ok {
+		if err := validResponse.VisitListTransfersResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // PostTransferTask operation middleware
 func (sh *strictHandler) PostTransferTask(ctx *gin.Context, params PostTransferTaskParams) {
 	var request PostTransferTaskRequestObject
@@ -552,6 +1594,33 @@ func (sh *strictHandler) DeleteTransferTask(ctx *gin.Context, scicatJobId string
 	}
 }
 
+// GetTransferTask operation middleware
+func (sh *strictHandler) GetTransferTask(ctx *gin.Context, scicatJobId string) {
+	var request GetTransferTaskRequestObject
+
+	request.ScicatJobId = scicatJobId
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetTransferTask(ctx, request.(GetTransferTaskRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetTransferTask")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetTransferTaskResponseObject); ok {
+		if err := validResponse.VisitGetTransferTaskResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // GetVersion operation middleware
 func (sh *strictHandler) GetVersion(ctx *gin.Context) {
 	var request GetVersionRequestObject
@@ -580,28 +1649,63 @@ func (sh *strictHandler) GetVersion(ctx *gin.Context) {
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+RX32/bRgz+V4jbgG6Aa6ft+uK3dP0BrwNmNMVeAj+cJcq6RLpTj1QyIfD/PvBOsuRI",
-	"XpKmb3uTZZHHj/zIj3enEldWzqJlUss75ZEqZwnDj09o0evig/fOf2n/kPeJs4yW5VFXVWESzcbZxRU5",
-	"K+8oybHU8lR5V6FnE92lyNoU7SMl3lRippYqqz3n6KH9YAYpbuvdztgdGJs5Xwb/aqa4qVAtFbE3dqf2",
-	"M1Uikd7h2CXnCChxQ/fJyHp/eOO2V5iw2surYzcadjEHrbMuPcE64gx4PpoCv7qvXlvK0E+Hk5kCgR1w",
-	"+xVogkp7BpeB7t96/FYjsZrdy56hi6YsjL0ee6cKE5MZJLjNMWSSc0PxQEOggVrLA+KtcwVqKymsNOfT",
-	"Acs/ElwX/AwMQ65JQGwRPBaazU3ElCOkmjUhA7naJwiZK1L0k2kXiMZjqpaX8fjZAN1mXJaZIkxqb7i5",
-	"kJTHfFwkwrvP2JzXEYCRuHPU8VSrS/FxkZjfNb88X69efsamj0ZXRn6HkgvHxhn4gsRwvl5B5nzAF13B",
-	"p8Jta4K1d/80c1gx1IQEMRpgd42WgomuOUfLbXPM5WjDRR/TkSM5SM3UDXqKp7+av56fSXlchVZXRi3V",
-	"m/nZ/I2KBQsZWPCAb5UjHoNYMeiicLcxpJZa0lcHurGmawK908YSg+5QBiA9PMFP6G9MgqCTxNWWIXE2",
-	"M7vaYwq3hvPhNy8IxJ22ifSdsDgkYZWqpVo74q5Rvmq6Doi8LpHRk1peTjHRpJLJzKAHqWtHyo5pOjGF",
-	"Yalu4MC3Gn3TUyB+9bH/qKcf+xpng3l1byy1JkLwmlD6NfqaIvXJBjI2dkdIfEgDJK4oMAmPreuOY4eK",
-	"TkMRJz8GyCCcR6MxaZf4rtW3OOSSRHKiBKE71iZ9QtCDlluv3j/m5AdRrKUe7bTyznHndBc7cVAWZ59A",
-	"sN7ui3O8jgPtNMwHwyQWVdA+yWW6Xrkt6IzRA9VJgkRZXTzEE12zO4/26jjDma4L7mJ64NgJudhvIjIk",
-	"fufS5hnLgAjKnybOLMNYhpc/e8zUUv206JeSRauyi3sS26u39l43j5PzVQauNMyYzgYTUFYFy8afkLA5",
-	"rDKovLsxqdg5WzTHxoUhxjRIJM1V0LfBEvX67OwZabpyW5ma/9Ecwo++Mw+BHdXvhPZG55uJRPVUKxoI",
-	"xMB0uKSIagjS3yK4qbodkrCYXCOD8avnGL/5fuO3zwn77fefLJtMXZbaN2rZ9dEorTPFeidKqA5dvhHL",
-	"g9wv7uI8/UPKt4/kKJAntuBW+4UYNYl/B4mIcjE8VNt04WT5Fh/hW6FU5l0ZZ2DcbOQdNcRYjhT9fbB8",
-	"iqZbx7kQsp1e7Q54JBYB3PPm6AertwXSIjUUHiJE0Y0AbgRsDu8aaEek7LovCFrTFH7JdEH46/ykMIcC",
-	"TIR3b3SOB8NxzDFEZ+FWEyTae4MpuJph2JH/z847ap5IYzomL/WkRsuhPida6bBo36kdTuzNHrn2NnZO",
-	"UnuPlqG16SZtJXv7qBc+If/d+v6hOjAIeKwEJyJsl/EHZaDzvXmEgg52kA5d3P67s4cX9uHNTS0vN8MC",
-	"7pCnblSdn0HpctQF561MDdyNroCXG+mx1ux+nv7qykTx4oqpTMPjS9rRApWr8USZdiJbw4F51Dvhfld5",
-	"yFFYZt3hitVCPnhqf+83+38DAAD//wcfbAawEQAA",
+	"H4sIAAAAAAAC/+xb3W/cNhL/VwjdAb0DlPU2aR7Ob+lHcm4L1Iide0mMA1ca7dKWSJUc2VkE/t8Pww+J",
+	"krgfjuP2AvQpXkkczgyH8/GbyaesUE2rJEg02emnTINplTRgf7wBCZrXP2mt9Fv/gp4XSiJIpD9529ai",
+	"4CiUPLk2StIzU2yg4fRXq1ULGoUjVwJyUfs/TaFFS8uy06zqNG5AM/9BzkpYdeu1kGsmZKV0Y+lneYbb",
+	"FrLTzKAWcp3d51kDxvA1zEniBhgQ3yx8Mlt93z9Rq2soMLunR2MynK2dDjyxoB672slp5flBNS1I4/ic",
+	"MVNwWUBd27dMVYyYW9dq1RmG3NzQI84qLmoomelWjTDGyTvWH0eEpsWEAmXXrEATndFWYQEzilVcDyoQ",
+	"EmENmjRo5ZpTNIBMOE5HJD2XfM1F8kCcWJfc3JyVRHX2gSnIXH5Wq+T7+zzT8HsnNJTZ6fsxtfHafFDH",
+	"1ewg8+y1qOFSXWouTQU6bR+VqIGhYui/Ytywlmt059E/JYbA4Ow4hLnYNrWQNwnttVCISoBhdxuwpo0b",
+	"YdyGwjDOjF/Zc75SqgYuifWW4ybNML0J9kO0ciaQbbghIVbANNAh3TqZNsBKjpzO0ahOF8AqVZegk/cg",
+	"1rndPo+kS6n338Br3Fwgx86keTXIEQKzrVYftzMF1tzgT2nzo0X02l+7QkkJBZJDCLJBC7IEWWyZwG8M",
+	"u+PCvq6UTpmlBl5u59sMp+N5ZLwogG6MP3STPCG/2etdnEfMDZTFiEl2tyFjMMg1PcoZrBfsQ3ZRiB84",
+	"MgP6VhTAOgP6Q8aUZh+yN85hFLUAiR+yg+foRE4d3lkJEgVuf+DFBl7XnUmYm+qwUI09wIq+sKrfABN+",
+	"LSto8exI7bdQ7vNQGhp1C6UjwECiFmByJmRRdyVto4EYhZKhugFpEl5rImnYNCXrb7rdcAnlz2o1Z4pL",
+	"1slKSEHLmdf9tVqxO4Eb1SHjsZue++Mi7e7vNhzZHTesVBIsLau6a7XK8gxk17jjaZXGLM/Io2ZXs9O0",
+	"NusD6uOc6Nhreqp54H6f0sjxJrVGa2/HMQxJ6BXUSq6tP5IqUuhjVefVP9OdC01J7R2Oa7yIItpnxbKa",
+	"r6BOvjG9Z3xQgHME++V7D+ktFEoWooa3Thv7rjApU/vvhQvkupsnGEWUxSTcuga6q+UoITBEPrIDinjK",
+	"QJTGDAoWCI0l+3cNVXaa/e1kyD1PfDJ1MsqkhhSNa823x53rRFCyJr5SGtOHLCSC1l2LKa8lSivftVol",
+	"5CLC0Wq2gkppGPsMtzsi+bqRCmZ8TOVUg+OyC45SXuzt9tAke3s4UVqVoqqhF/AIBfZpFWlGKmSNkgKV",
+	"pqRSlmytyOtKuAvPH6Q0DWYri0OMGBR13TOiXZkRvBXlNapbe78TmBXOIz2IGRQNJFMOmTJTzSUFA1vs",
+	"ZKdZyRGeWQqH4rz/KDqFSA/TM5/Y1dj884kDSHsdQ4d10TUN19u9TkeD6RpSbhRjg0LpUrjkp2tnbmj/",
+	"FdeWA6J74Gbb/Z0tHGXnXrSyLxoSZ2puRNs+gOaF+34fyQNmMmjsDjR46aF8pK04zQzy7DnqcncRFRVJ",
+	"NvwLE99mXyKOz/aR9WGeuWImqTAN40tL9hH0xSqtmiiFsE/7kjK7OqS5cRI1rUsdTyklTg3gQA461meh",
+	"urq0TtJWd+HQxhp90jQxJVKQ5ZUtlaA8yi5WQDfWRk90uX0Ik8LZ+YoXN2utOjmX8DoIMK+0opxdlKHa",
+	"7Dd3meeO5Oydruc04wrbfTYjynFU0u5X6rVX57DlPo2eITRz2Gy1RTCXCnmcakYIjnvvSWgo018VGjhC",
+	"+comise4jjzz+MG5KMfJwsHIV4JBIW0Uec0pxOE2uY4wjH2SufeHJDvoUiKQcPZO3UnQb7Tq2t2v3xnQ",
+	"ybfu3u8VcSgEgufxIECWZ/42RD9sYvnfoSKJUhRy+N5L+KLR/uFzcfu3kLe8FqU33GRVFCh+1n1K2UjX",
+	"lg8zq2lEGhjKsxnj8yvyqzCYcjgtXzu0yX9nZl4Eg53tAib6pazhWPSIRyVqhDR2Omx2bD4wuumzm7ND",
+	"NySL436fZi6Q66O8MYXFhCN+YrebdIxzeejKQNFpgdsL0plj5cLGpl9g+6pz2KggTjbAHaApeUM0HFfP",
+	"Xp2fPfsFIt/MW0G/LbxP/YS5TJTqsFfnZxaZiwT0mNs5+foFO0PWGTDMcePhKbuEd7gBib4RsqCtBdYD",
+	"TyNCtFGWZ7egjdv928XzxdJ6mxYkb0V2mr1YLBcvMocFWw2c8LIR8iTAb88c/GYFqQEhVawTzGYYr+sg",
+	"TWfIuO3Kkq22ToCcGcXgFrT/TcFag1H1rfvIr7XZHFOSCTRMwkcMCKlDLnmFHkblRQHGMArlrQ2eftcN",
+	"l2soF+ytMwLDGqCLZzaiZZZyDxYXSlZi3dkckoT2tEirZJ5WxWSYmYUuR2Cmy22HBtbz5fJB/ap9VzeB",
+	"miYaRlYA+sJes4BM3ufZd8tvd+3Qs3ySbLjZxS8+f/HL5fIxiz97Z7rKoUx0GG0aQUa+NuQS7GFnV7TO",
+	"W3soka15rwGTkFSnJWELPi2zTYMWtFClKKY19grwDkBG7svkY/CKIIihe2GLBFZRUmrfVOLjFzbhN4BT",
+	"HO8JTXi61Q77nWjNo61/2fAa0CM3pJBw1tbixio7YNSu9jxs0qLYpLGTgARQPRgBKbk1UrfOfuKre/94",
+	"++Utd4QFPanhjnbaabfuwoYT+8tig8X6Jx6Vc4DIKLtMWOvGtnhPanG721ZNVxQApaHOOXV+6N9Rx1N3",
+	"Utr2Jq+N8j3Pab82uGOlfZqUsrVfxS1IMOYprWzU1N5hY1PJRhlrdvr+KlZ/7ZmmVas41DndjhXdt6j3",
+	"a1rJAiIda3DpXEjTZBlyTfpz2s1esHcSRU3rZW5TQ2X73+E9g4+0wNL3+WkfEsfC2K6Oa9B5nOTl8sUO",
+	"L8FL8f9zdFOVRNftjzci14kIQwB7bUkHNe43JoyAxqQh1YLOeYwsb7gsa18T0JyKkAa5LPqgYPnNqSsD",
+	"BlkltMEFe2fzegMwIYYb2DJ1J5ny9SbXQL8d/SHUCO1jS+4ijTCoOSpPk0cdGrNgl46LtSYFCLoF9dbH",
+	"4cB3DNhxZoRc1wNbc8skIOEyBgu45g0g/Th9P1Wa3Y40Fwdh15wWxu9rAZjsNPu9A70ditL+5WA+s8p4",
+	"ul3DP4qma1gKm0DFXIawY79aNAJH25VQ8a7G7PTb5TIPtO0v+imk/5kaszgGLEFl84wd7KiqMrCDn3j7",
+	"ZWL7qyd0GCM8acc17WV8dCbxiGTgfhxQYhOMfEB4ll3d51mrUhjZGfU2a3XnEAvv/izI1aNDrgSiSt9Q",
+	"F9bHFAsLDOiHvWl+PokXheokxlljP7Phv/lmcCezO3iuhjt46Sds9l3DoXysBGhGJtaD9X7GLWCxOy7j",
+	"GLCNISnUHYytNN450CVr7wxQnuNopYCunaN7vtURweOsUHUNbhDFkw4QVIS6pkQhIl9GkIido6UZUL8w",
+	"ZOgaPBiB9TuOwIJn56J8ANMRInd+9uMxOx+U4pzOw88SaqVwMpYbHYuSDzCwYd1bpfDcjVLuFvMgmzY1",
+	"YFwXG5q3IrzVIW02HTSm6upDdsI7VK/c+rQXdjwd2DYxBjln1sUldzeUDLVAjBUb5ttQrlR1KPQYPN7T",
+	"GFyw11zUnQZjc4o+/PsTCoN7cS6Q6qoktWS2skjrp+K1gWPE76T4vQN2A9vgpLauhyD6bJMqcNT2MQ/P",
+	"Ip5545YHCCBI5W0TuK7FkK1bvwrc3sSQQvrZmalNTJHysxKaViHIYuuh8kHwhn/8FeQaN9np85cv51fp",
+	"yhk0GPxeldsHBeTJrKioIbRzjmqeTGa6U+2TgwP9ZxVTrvORx20RYHRgesfM9IKdVZR+3oqS1tlscLSY",
+	"4jLNGIgazMLPnjxt3hK6PQkRB99Qb51hEJgzDvPE4/Pl8y/OVj8TsCOlimdsee8N+hoznreaTgzM3IHL",
+	"ypaPwXf+LHDou+W/vnJkKfigqV0lU9K4MD35FA2a3O/rYPlslQ69My4yuG53vCmX5YnSzNHoHSYN+9gf",
+	"bi/7zGwNQjPLQX+0Kx+ShUqFG9eQF2FkZJreDFM0nx35f5J8VYM5KYWxfzgRLWRGws0EW7Dvt8wHrdxh",
+	"bH5pyf5hY9g/FztTSXsACfaGaLejFhvz7Fj0o7EF13ZaWHXIYpf0FV/bL1XGOTM2Y+M1g1GDRL1NX6V8",
+	"f9MgSn3Ip/aQyeh/NOXMDsYVSpdQhp7uaFh/BuI9tEo7NEfyBW/OH4ESuHGN/SjBVx2OvvvzL0Xfq4hQ",
+	"vMhidoSVfori0+GLUXRag8Qe2Z7857SZ0f/H036kgY2z3ojhuS3t4NBDKQeHagLtqyMS4aiCDNK5SiTs",
+	"Hf/P233ANJ1basrlttfeHKgek5vN97y/ur/ql0319Fs4JuP+w6ObYRpP4IzK3012nx9HhJL/EbzmiURX",
+	"/BAh/18UA0DmRe4p+d976diBojEiPrHUIBt9lN1f3f9vAI0jwVfFPQAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
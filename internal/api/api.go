@@ -1,24 +1,35 @@
 package api
 
 //go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen --config=cfg.yaml openapi.yaml
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen --config=client.cfg.yaml openapi.yaml
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/SwissOpenEM/globus"
 	config "github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/serviceuser"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tasks"
 	util "github.com/SwissOpenEM/scicat-globus-proxy/internal/util"
 )
 
 type ServerHandler struct {
 	version           string
+	readiness         *Readiness
 	globusClient      globus.GlobusClient
-	scicatUrl         string
+	scicat            *scicat.Client
 	scicatServiceUser serviceuser.ScicatServiceUser
-	facilities        map[string]Facility
+	facilities        *atomic.Pointer[map[string]Facility]
 	taskPool          tasks.TaskPool
+	store             *store.Store
+	adminGroups       []string
+	identityCache     *IdentityCache
+	jwtAuth           *JwtAuthenticator
+	restoreSummary    *atomic.Pointer[tasks.RestoreSummary]
+	reconciler        *atomic.Pointer[tasks.Reconciler]
 	addTaskMutex      *sync.Mutex
 }
 
@@ -40,49 +51,104 @@ func NewFacility(config config.FacilityConfig) (*Facility, error) {
 	facility.Direction = config.Direction
 	facility.AccessPath, err = util.NewTypedTemplate[accessPathContext](config.AccessPath)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("accessPath: %w", err)
 	}
 	facility.AccessValue, err = util.NewTypedTemplate[accessPathContext](config.AccessValue)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("accessValue: %w", err)
 	}
 	facility.SourcePath, err = util.NewTypedTemplate[facilityPathContext](config.SourcePath)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("sourcePath: %w", err)
 	}
 	facility.DestinationPath, err = util.NewTypedTemplate[facilityPathContext](config.DestinationPath)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("destinationPath: %w", err)
 	}
 	return facility, nil
 }
 
+// Build the facilities from their configuration, keyed by name
+func NewFacilities(configs []config.FacilityConfig) (map[string]Facility, error) {
+	facilities := make(map[string]Facility, len(configs))
+	for _, facConf := range configs {
+		if _, exists := facilities[facConf.Name]; exists {
+			return nil, fmt.Errorf("duplicate facility %s", facConf.Name)
+		}
+		facility, err := NewFacility(facConf)
+		if err != nil {
+			return nil, fmt.Errorf("unable to configure facility %s: %w", facConf.Name, err)
+		}
+		facilities[facConf.Name] = *facility
+	}
+	return facilities, nil
+}
+
 var _ StrictServerInterface = ServerHandler{}
 
 func NewServerHandler(
 	version string,
+	readiness *Readiness,
 	globusClient globus.GlobusClient,
-	scicatUrl string,
+	scicatClient *scicat.Client,
 	scicatServiceUser serviceuser.ScicatServiceUser,
 	facilities *map[string]Facility,
-	taskPool tasks.TaskPool) (ServerHandler, error) {
+	taskPool tasks.TaskPool,
+	st *store.Store,
+	adminGroups []string,
+	identityCache *IdentityCache,
+	jwtAuth *JwtAuthenticator) (ServerHandler, error) {
 	// create server with service client
 	var err error
 	if !globusClient.IsClientSet() {
 		return ServerHandler{}, fmt.Errorf("AUTH error: Client is nil")
 	}
 
+	facilitiesPtr := &atomic.Pointer[map[string]Facility]{}
+	facilitiesPtr.Store(facilities)
+
 	return ServerHandler{
 		version:           version,
+		readiness:         readiness,
 		globusClient:      globusClient,
-		scicatUrl:         scicatUrl,
+		scicat:            scicatClient,
 		scicatServiceUser: scicatServiceUser,
-		facilities:        *facilities,
+		facilities:        facilitiesPtr,
 		taskPool:          taskPool,
+		store:             st,
+		adminGroups:       adminGroups,
+		identityCache:     identityCache,
+		jwtAuth:           jwtAuth,
+		restoreSummary:    &atomic.Pointer[tasks.RestoreSummary]{},
+		reconciler:        &atomic.Pointer[tasks.Reconciler]{},
 		addTaskMutex:      &sync.Mutex{},
 	}, err
 }
 
+// Construct a handler that only serves the health endpoints, while the proxy waits for its dependencies
+func NewStartupServerHandler(version string, readiness *Readiness) ServerHandler {
+	facilitiesPtr := &atomic.Pointer[map[string]Facility]{}
+	facilitiesPtr.Store(&map[string]Facility{})
+	return ServerHandler{
+		version:        version,
+		readiness:      readiness,
+		facilities:     facilitiesPtr,
+		restoreSummary: &atomic.Pointer[tasks.RestoreSummary]{},
+		reconciler:     &atomic.Pointer[tasks.Reconciler]{},
+		addTaskMutex:   &sync.Mutex{},
+	}
+}
+
+// Replace the facilities, eg. after the config was reloaded. Requests in progress
+// keep using the facilities they started with.
+func (s ServerHandler) SetFacilities(facilities map[string]Facility) {
+	s.facilities.Store(&facilities)
+}
+
+func (s ServerHandler) getFacilities() map[string]Facility {
+	return *s.facilities.Load()
+}
+
 // Helper to get a pointer to a literal value
 func getPointerOrNil[T comparable](v T) *T {
 	var a T
@@ -2,10 +2,99 @@ package api
 
 import (
 	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	gin "github.com/gin-gonic/gin"
 )
 
+// Whether the proxy finished starting, and what it's waiting for otherwise
+type Readiness struct {
+	mutex      sync.Mutex
+	ready      bool
+	waitingFor string
+	lastError  string
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// Report that the proxy is waiting for a dependency. err is the last error connecting to it, if any.
+func (r *Readiness) SetWaiting(dependency string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.waitingFor = dependency
+	r.lastError = ""
+	if err != nil {
+		r.lastError = err.Error()
+	}
+}
+
+func (r *Readiness) SetReady() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ready = true
+	r.waitingFor = ""
+	r.lastError = ""
+}
+
+func (r *Readiness) IsReady() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.ready
+}
+
+func (r *Readiness) status() HealthStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return HealthStatus{
+		Ready:      r.ready,
+		WaitingFor: getPointerOrNil(r.waitingFor),
+		LastError:  getPointerOrNil(r.lastError),
+	}
+}
+
+// Paths that are served while the proxy is starting
+var startupPaths = []string{"/version", "/health/", "/openapi.yaml", "/docs/"}
+
+// Reject requests with 503 until the proxy is ready, except for the health endpoints and the docs.
+// This runs before the request validation, which would otherwise try to authenticate against SciCat.
+func requireReady(readiness *Readiness) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if readiness.IsReady() {
+			c.Next()
+			return
+		}
+		for _, path := range startupPaths {
+			if strings.HasPrefix(c.Request.URL.Path, path) {
+				c.Next()
+				return
+			}
+		}
+		response := GeneralErrorResponse{Message: getPointerOrNil("the proxy is starting")}
+		if status := readiness.status(); status.WaitingFor != nil {
+			response.Details = getPointerOrNil("waiting for " + *status.WaitingFor)
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, response)
+	}
+}
+
 func (s ServerHandler) GetVersion(ctx context.Context, request GetVersionRequestObject) (GetVersionResponseObject, error) {
 	return GetVersion200JSONResponse{
 		Version: s.version,
 	}, nil
 }
+
+func (s ServerHandler) GetLiveness(ctx context.Context, request GetLivenessRequestObject) (GetLivenessResponseObject, error) {
+	return GetLiveness200JSONResponse(s.readiness.status()), nil
+}
+
+func (s ServerHandler) GetReadiness(ctx context.Context, request GetReadinessRequestObject) (GetReadinessResponseObject, error) {
+	status := s.readiness.status()
+	if !status.Ready {
+		return GetReadiness503JSONResponse(status), nil
+	}
+	return GetReadiness200JSONResponse(status), nil
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/logging"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartupRouter(t *testing.T) {
+	readiness := NewReadiness()
+	readiness.SetWaiting("SciCat service user login", errors.New("connection refused"))
+	handler := NewStartupServerHandler("test", readiness)
+	router, err := NewRouter(&handler, scicat.NewClient("http://scicat.invalid", config.NewScicatConfig(), http.DefaultTransport))
+	assert.NoError(t, err)
+
+	get := func(path string) (int, map[string]any) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		body := map[string]any{}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		return recorder.Code, body
+	}
+
+	code, body := get("/health/live")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, false, body["ready"])
+
+	code, body = get("/health/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "SciCat service user login", body["waitingFor"])
+	assert.Equal(t, "connection refused", body["lastError"])
+
+	code, body = get("/version")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "test", body["version"])
+
+	code, body = get("/transfer")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "the proxy is starting", body["message"])
+	assert.Equal(t, "waiting for SciCat service user login", body["details"])
+
+	readiness.SetReady()
+	code, body = get("/health/ready")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, body["ready"])
+	assert.Nil(t, body["lastError"])
+}
+
+func TestSwitchableHandler(t *testing.T) {
+	respond := func(status int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(status) })
+	}
+	handler := NewSwitchableHandler(respond(http.StatusServiceUnavailable))
+	get := func() int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, get())
+	handler.Switch(respond(http.StatusOK))
+	assert.Equal(t, http.StatusOK, get())
+}
+
+func TestRequestIdHeader(t *testing.T) {
+	readiness := NewReadiness()
+	handler := NewStartupServerHandler("test", readiness)
+	router, err := NewRouter(&handler, scicat.NewClient("http://scicat.invalid", config.NewScicatConfig(), http.DefaultTransport))
+	assert.NoError(t, err)
+
+	get := func(requestId string) string {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/version", nil)
+		if requestId != "" {
+			req.Header.Set(logging.RequestIdHeader, requestId)
+		}
+		router.ServeHTTP(recorder, req)
+		return recorder.Header().Get(logging.RequestIdHeader)
+	}
+
+	assert.Equal(t, "client-request-1", get("client-request-1"))
+	generated := get("")
+	assert.Len(t, generated, 32)
+	assert.NotEqual(t, "invalid id", get("invalid id"))
+}
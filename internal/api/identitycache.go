@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+)
+
+// Cache of the SciCat users resolved from tokens, keyed by the hash of the token,
+// so polling clients don't make SciCat resolve their token on every request
+type IdentityCache struct {
+	mutex       sync.Mutex
+	entries     map[[sha256.Size]byte]identityEntry
+	ttl         time.Duration
+	negativeTtl time.Duration
+	maxEntries  int
+	now         func() time.Time
+}
+
+// A resolved user, or the error of a rejected token
+type identityEntry struct {
+	user    scicat.User
+	err     error
+	expires time.Time
+}
+
+// Construct a cache, or nil if it's disabled. A nil cache resolves every token.
+func NewIdentityCache(conf config.IdentityCacheConfig) *IdentityCache {
+	if conf.Disabled {
+		return nil
+	}
+	return &IdentityCache{
+		entries:     map[[sha256.Size]byte]identityEntry{},
+		ttl:         time.Duration(conf.Ttl) * time.Second,
+		negativeTtl: time.Duration(conf.NegativeTtl) * time.Second,
+		maxEntries:  max(int(conf.MaxEntries), 1),
+		now:         time.Now,
+	}
+}
+
+// Get the user of a token from the cache, or resolve and cache it. Users are cached until the
+// token expires, at most for the ttl. Tokens rejected by SciCat are cached for the negative ttl,
+// other errors aren't cached.
+func (c *IdentityCache) Lookup(ctx context.Context, token string, resolve func(context.Context, string) (scicat.User, error)) (scicat.User, error) {
+	if c == nil {
+		return resolve(ctx, token)
+	}
+	key := sha256.Sum256([]byte(token))
+	now := c.now()
+
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	c.mutex.Unlock()
+	if ok && now.Before(entry.expires) {
+		if entry.err != nil {
+			return scicat.User{}, entry.err
+		}
+		user := entry.user
+		user.ScicatToken = token
+		return user, nil
+	}
+
+	user, err := resolve(ctx, token)
+	switch {
+	case err == nil:
+		expires := now.Add(c.ttl)
+		if tokenExpiry, ok := jwtExpiry(token); ok && tokenExpiry.Before(expires) {
+			expires = tokenExpiry
+		}
+		cached := user
+		cached.ScicatToken = ""
+		c.store(key, identityEntry{user: cached, expires: expires}, now)
+	case isRejectedToken(err):
+		c.store(key, identityEntry{err: err, expires: now.Add(c.negativeTtl)}, now)
+	}
+	return user, err
+}
+
+func (c *IdentityCache) store(key [sha256.Size]byte, entry identityEntry, now time.Time) {
+	if !now.Before(entry.expires) {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	// still full, make room for the new entry
+	for k := range c.entries {
+		if len(c.entries) < c.maxEntries {
+			break
+		}
+		delete(c.entries, k)
+	}
+	c.entries[key] = entry
+}
+
+// Remove all entries, eg. after the groups of users changed. Returns the number of removed entries.
+func (c *IdentityCache) Flush() int {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	flushed := len(c.entries)
+	clear(c.entries)
+	return flushed
+}
+
+// Whether SciCat rejected the token, as opposed to being unavailable
+func isRejectedToken(err error) bool {
+	var httpErr *scicat.HttpError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	return httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden
+}
+
+// Expiry of a token that is a JWT with an exp claim. The signature isn't checked,
+// the token was accepted by SciCat.
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/stretchr/testify/assert"
+)
+
+// An unsigned JWT expiring at exp
+func testJwt(exp time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, `{"sub":"user","exp":%d}`, exp.Unix()))
+	return "eyJhbGciOiJub25lIn0." + payload + ".sig"
+}
+
+func TestIdentityCache(t *testing.T) {
+	now := time.Now()
+	cache := NewIdentityCache(config.NewAuthConfig().IdentityCache)
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	resolve := func(ctx context.Context, token string) (scicat.User, error) {
+		calls++
+		switch token {
+		case "invalid":
+			return scicat.User{}, &scicat.HttpError{StatusCode: http.StatusUnauthorized}
+		case "unavailable":
+			return scicat.User{}, &scicat.HttpError{StatusCode: http.StatusBadGateway}
+		}
+		user := scicat.User{ID: "user", ScicatToken: token}
+		return user, nil
+	}
+
+	user, err := cache.Lookup(context.Background(), "token", resolve)
+	assert.NoError(t, err)
+	user, err = cache.Lookup(context.Background(), "token", resolve)
+	assert.NoError(t, err)
+	assert.Equal(t, "user", user.ID)
+	assert.Equal(t, "token", user.ScicatToken)
+	assert.Equal(t, 1, calls)
+
+	// rejected tokens are cached for a shorter time, other errors aren't cached
+	for range 2 {
+		_, err = cache.Lookup(context.Background(), "invalid", resolve)
+		assert.Error(t, err)
+		_, err = cache.Lookup(context.Background(), "unavailable", resolve)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 4, calls)
+	now = now.Add(time.Minute)
+	_, _ = cache.Lookup(context.Background(), "invalid", resolve)
+	_, _ = cache.Lookup(context.Background(), "token", resolve)
+	assert.Equal(t, 5, calls)
+
+	// users aren't cached after their token expires
+	jwt := testJwt(now.Add(time.Minute))
+	_, _ = cache.Lookup(context.Background(), jwt, resolve)
+	_, _ = cache.Lookup(context.Background(), jwt, resolve)
+	assert.Equal(t, 6, calls)
+	now = now.Add(2 * time.Minute)
+	_, _ = cache.Lookup(context.Background(), jwt, resolve)
+	assert.Equal(t, 7, calls)
+
+	assert.Equal(t, 3, cache.Flush())
+	_, _ = cache.Lookup(context.Background(), "token", resolve)
+	assert.Equal(t, 8, calls)
+}
+
+func TestIdentityCacheMaxEntries(t *testing.T) {
+	conf := config.NewAuthConfig().IdentityCache
+	conf.MaxEntries = 2
+	cache := NewIdentityCache(conf)
+	resolve := func(ctx context.Context, token string) (scicat.User, error) {
+		return scicat.User{ID: token}, nil
+	}
+	for _, token := range []string{"a", "b", "c"} {
+		_, _ = cache.Lookup(context.Background(), token, resolve)
+	}
+	assert.Len(t, cache.entries, 2)
+
+	conf.Disabled = true
+	assert.Nil(t, NewIdentityCache(conf))
+	var disabled *IdentityCache
+	user, err := disabled.Lookup(context.Background(), "a", resolve)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", user.ID)
+	assert.Equal(t, 0, disabled.Flush())
+}
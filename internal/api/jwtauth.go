@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/jwks"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Tolerated clock difference to the issuer of the tokens
+const jwtLeeway = 30 * time.Second
+
+// Signature algorithms of tokens signed with the keys of a key set
+var jwtMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Authenticates requests by verifying their token as a JWT, without contacting SciCat
+type JwtAuthenticator struct {
+	parser *jwt.Parser
+	keys   *jwks.KeySet
+	conf   config.JwtConfig
+}
+
+// Construct an authenticator with the keys of the configured key set. Keys from a url are
+// fetched with the client when the first token is verified.
+func NewJwtAuthenticator(conf config.JwtConfig, httpClient *http.Client) (*JwtAuthenticator, error) {
+	var keys *jwks.KeySet
+	if conf.JwksFile != "" {
+		var err error
+		keys, err = jwks.NewFileKeySet(conf.JwksFile)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		keys = jwks.NewRemoteKeySet(conf.JwksUrl, httpClient, time.Duration(conf.RefreshInterval)*time.Second)
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(jwtMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithAudience(conf.Audience),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if conf.Issuer != "" {
+		options = append(options, jwt.WithIssuer(conf.Issuer))
+	}
+	return &JwtAuthenticator{
+		parser: jwt.NewParser(options...),
+		keys:   keys,
+		conf:   conf,
+	}, nil
+}
+
+// Verify the signature, expiry and audience of the token, and build the user from its claims.
+// If the claims lack the username or the groups, the user is resolved with fallback.
+func (a *JwtAuthenticator) Authenticate(ctx context.Context, token string, fallback func(context.Context, string) (scicat.User, error)) (scicat.User, error) {
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(token, claims, a.keys.Keyfunc); err != nil {
+		return scicat.User{}, fmt.Errorf("unable to authenticate SciCat user. The access token provided with the request is invalid: %w", err)
+	}
+
+	username, hasUsername := claimValue(claims, a.conf.UsernameClaim).(string)
+	groups, hasGroups := claimStrings(claimValue(claims, a.conf.GroupsClaim))
+	if !hasUsername || username == "" || !hasGroups {
+		return fallback(ctx, token)
+	}
+
+	user := scicat.User{ScicatToken: token}
+	user.ID, _ = claims.GetSubject()
+	user.AuthStrategy = "jwt"
+	user.ExternalID = user.ID
+	user.Profile.ID = user.ID
+	user.Profile.Username = username
+	user.Profile.Email, _ = claimValue(claims, a.conf.EmailClaim).(string)
+	user.Profile.DisplayName, _ = claimValue(claims, a.conf.NameClaim).(string)
+	user.Profile.AccessGroups = groups
+
+	oidc := &user.Profile.OidcClaims
+	oidc.Sub = user.ID
+	oidc.Iss, _ = claims.GetIssuer()
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		oidc.Exp = int(exp.Unix())
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		oidc.Iat = int(iat.Unix())
+	}
+	oidc.PreferredUsername = username
+	oidc.Email = user.Profile.Email
+	oidc.Name = user.Profile.DisplayName
+	oidc.AccessGroups = groups
+	return user, nil
+}
+
+// Value of a claim, where the path separates nested claims by dots, or nil
+func claimValue(claims jwt.MapClaims, path string) any {
+	var value any = map[string]any(claims)
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+// Convert a claim that is a list of strings, or a single string
+func claimStrings(value any) ([]string, bool) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, true
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, s)
+		}
+		return values, true
+	default:
+		return nil, false
+	}
+}
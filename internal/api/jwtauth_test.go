@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJwtAuthenticator(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	jwks := `{"keys": [{"kty": "OKP", "kid": "key1", "crv": "Ed25519", "x": "` + base64.RawURLEncoding.EncodeToString(public) + `"}]}`
+	assert.NoError(t, os.WriteFile(jwksFile, []byte(jwks), 0o600))
+
+	conf := config.NewAuthConfig().Jwt
+	conf.JwksFile = jwksFile
+	conf.Audience = "scicat"
+	conf.GroupsClaim = "realm_access.groups"
+	auth, err := NewJwtAuthenticator(conf, nil)
+	assert.NoError(t, err)
+
+	sign := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = "key1"
+		signed, err := token.SignedString(private)
+		assert.NoError(t, err)
+		return signed
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":                "1234",
+			"aud":                "scicat",
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"preferred_username": "jdoe",
+			"email":              "jdoe@example.org",
+			"realm_access":       map[string]any{"groups": []string{"group1", "group2"}},
+		}
+	}
+	fallbacks := 0
+	fallback := func(ctx context.Context, token string) (scicat.User, error) {
+		fallbacks++
+		user := scicat.User{ScicatToken: token}
+		user.Profile.Username = "resolved"
+		return user, nil
+	}
+
+	token := sign(claims())
+	user, err := auth.Authenticate(context.Background(), token, fallback)
+	assert.NoError(t, err)
+	assert.Equal(t, "jdoe", user.Profile.Username)
+	assert.Equal(t, "jdoe@example.org", user.Profile.Email)
+	assert.Equal(t, []string{"group1", "group2"}, user.Profile.AccessGroups)
+	assert.Equal(t, "1234", user.ID)
+	assert.Equal(t, token, user.ScicatToken)
+	assert.Equal(t, 0, fallbacks)
+
+	// the user is resolved by SciCat if the claims lack the groups
+	incomplete := claims()
+	delete(incomplete, "realm_access")
+	user, err = auth.Authenticate(context.Background(), sign(incomplete), fallback)
+	assert.NoError(t, err)
+	assert.Equal(t, "resolved", user.Profile.Username)
+	assert.Equal(t, 1, fallbacks)
+
+	// invalid tokens are rejected without asking SciCat
+	expired := claims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	otherAudience := claims()
+	otherAudience["aud"] = "other"
+	_, forged, _ := ed25519.GenerateKey(rand.Reader)
+	forgedToken, _ := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims()).SignedString(forged)
+	for _, invalid := range []string{sign(expired), sign(otherAudience), forgedToken, "not-a-jwt"} {
+		_, err = auth.Authenticate(context.Background(), invalid, fallback)
+		assert.Error(t, err)
+	}
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	_, err = auth.Authenticate(context.Background(), unsigned, fallback)
+	assert.True(t, errors.Is(err, jwt.ErrTokenSignatureInvalid) || errors.Is(err, jwt.ErrTokenUnverifiable))
+	assert.Equal(t, 1, fallbacks)
+}
@@ -14,7 +14,13 @@ import (
 // HTTP header to specify SciCat API key
 const SCICAT_AUTH_HEADER = "SciCat-API-Key"
 
-func ScicatTokenAuthMiddleware(scicatUrl string) openapi3filter.AuthenticationFunc {
+// Authenticate requests with the SciCat token in their header. Tokens are verified by jwtAuth
+// if it isn't nil, otherwise they are resolved by SciCat. The users resolved by SciCat are
+// cached, unless the cache is nil.
+func ScicatTokenAuthMiddleware(scicatClient *scicat.Client, identityCache *IdentityCache, jwtAuth *JwtAuthenticator) openapi3filter.AuthenticationFunc {
+	resolve := func(ctx context.Context, token string) (scicat.User, error) {
+		return identityCache.Lookup(ctx, token, scicatClient.GetUserIdentity)
+	}
 	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
 		// Get scicat API key from the header
 		ginCtx, ok := ctx.Value(ginmiddleware.GinContextKey).(*gin.Context)
@@ -26,12 +32,13 @@ func ScicatTokenAuthMiddleware(scicatUrl string) openapi3filter.AuthenticationFu
 			return fmt.Errorf("SciCat authentication is required. Specify a SciCat token in the '%s' header", SCICAT_AUTH_HEADER)
 		}
 
-		scicat := scicat.ScicatService{
-			Url:   scicatUrl,
-			Token: scicatApiKey,
+		var user scicat.User
+		var err error
+		if jwtAuth != nil {
+			user, err = jwtAuth.Authenticate(ginCtx.Request.Context(), scicatApiKey, resolve)
+		} else {
+			user, err = resolve(ginCtx.Request.Context(), scicatApiKey)
 		}
-
-		user, err := scicat.GetUserIdentity()
 		if err != nil {
 			return err
 		}
@@ -1,24 +1,38 @@
 package api
 
 import (
+	"crypto/tls"
 	"embed"
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"slices"
+	"sync/atomic"
 
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/logging"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
 	"github.com/getkin/kin-openapi/openapi3filter"
-	"github.com/gin-contrib/slog"
+	sloggin "github.com/gin-contrib/slog"
 	gin "github.com/gin-gonic/gin"
 	middleware "github.com/oapi-codegen/gin-middleware"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Paths that aren't logged or traced, as they are polled by probes
+var quietPaths = []string{"/version", "/health/live", "/health/ready"}
+
 //go:embed openapi.yaml
 var swaggerYAML embed.FS
 
-func NewServer(api *ServerHandler, port uint, scicatUrl string) (*http.Server, error) {
+// Construct the router serving the API of the handler
+func NewRouter(api *ServerHandler, scicatClient *scicat.Client) (http.Handler, error) {
 	swagger, err := GetSwagger()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
@@ -29,17 +43,30 @@ func NewServer(api *ServerHandler, port uint, scicatUrl string) (*http.Server, e
 	// that server names match.
 	swagger.Servers = nil
 
-	// Create gin router
+	// Create gin router. The handlers get the request context through the gin
+	// context, which carries the request id and the span of the request.
 	r := gin.New()
+	r.ContextWithFallback = true
+
+	r.Use(otelgin.Middleware(tracing.ServiceName,
+		otelgin.WithFilter(func(req *http.Request) bool { return !slices.Contains(quietPaths, req.URL.Path) }),
+	))
+
+	r.Use(requestId())
+
+	r.Use(clientCertificate())
 
 	r.Use(
-		slog.SetLogger(
-			slog.WithSkipPath([]string{"/version", "/health"}),
-			slog.WithRequestHeader(false),
+		sloggin.SetLogger(
+			sloggin.WithLogger(func(*gin.Context, *slog.Logger) *slog.Logger { return slog.Default() }),
+			sloggin.WithSkipPath(quietPaths),
+			sloggin.WithRequestHeader(false),
 		))
 
 	r.Use(gin.Recovery())
 
+	r.Use(requireReady(api.readiness))
+
 	r.GET("/openapi.yaml", func(c *gin.Context) {
 		http.FileServer(http.FS(swaggerYAML)).ServeHTTP(c.Writer, c.Request)
 	})
@@ -49,15 +76,65 @@ func NewServer(api *ServerHandler, port uint, scicatUrl string) (*http.Server, e
 	r.Use(
 		middleware.OapiRequestValidatorWithOptions(swagger, &middleware.Options{
 			Options: openapi3filter.Options{
-				AuthenticationFunc: ScicatTokenAuthMiddleware(scicatUrl),
+				AuthenticationFunc: ScicatTokenAuthMiddleware(scicatClient, api.identityCache, api.jwtAuth),
 			},
 		}),
 	)
 
 	RegisterHandlers(r, NewStrictHandler(api, []StrictMiddlewareFunc{}))
 
+	return r, nil
+}
+
+// Use the request id sent by the client, or generate one. It's returned in the
+// response and attached to the request context, so that it's logged by all handlers.
+func requestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(logging.RequestIdHeader)
+		if !logging.IsValidRequestId(id) {
+			id = logging.NewRequestId()
+		}
+		c.Header(logging.RequestIdHeader, id)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request.id", id))
+		c.Request = c.Request.WithContext(logging.WithRequestId(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// Handler that can be replaced while the server is running, eg. the startup router
+// by the full router once the dependencies of the proxy are available
+type SwitchableHandler struct {
+	handler atomic.Pointer[http.Handler]
+}
+
+func NewSwitchableHandler(handler http.Handler) *SwitchableHandler {
+	s := &SwitchableHandler{}
+	s.Switch(handler)
+	return s
+}
+
+// Serve all following requests with handler. Requests in progress finish with the previous handler.
+func (s *SwitchableHandler) Switch(handler http.Handler) {
+	s.handler.Store(&handler)
+}
+
+func (s *SwitchableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*s.handler.Load()).ServeHTTP(w, r)
+}
+
+// Construct the server of the API. It serves TLS if tlsConfig isn't nil.
+func NewServer(handler http.Handler, port uint, tlsConfig *tls.Config) *http.Server {
 	return &http.Server{
-		Handler: r,
-		Addr:    net.JoinHostPort("0.0.0.0", fmt.Sprint(port)),
-	}, nil
+		Handler:   handler,
+		Addr:      net.JoinHostPort("0.0.0.0", fmt.Sprint(port)),
+		TLSConfig: tlsConfig,
+	}
+}
+
+// Accept connections until the server is shut down, over TLS if the server has a TLS config
+func ListenAndServe(server *http.Server) error {
+	if server.TLSConfig != nil {
+		return server.ListenAndServeTLS("", "")
+	}
+	return server.ListenAndServe()
 }
@@ -2,19 +2,36 @@
 package api
 
 import (
+	"net/url"
+	"path"
+
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/util"
 )
 
 // Context for sourcePath and destinationPath
 type facilityPathContext struct {
-	DatasetFolder        string
-	SourceFolder         string
-	RelativeSourceFolder string
-	Pid                  string
-	PidShort             string
-	PidPrefix            string
-	PidEncoded           string
-	Username             string
+	DatasetFolder        string `yaml:"datasetFolder"`
+	SourceFolder         string `yaml:"sourceFolder"`
+	RelativeSourceFolder string `yaml:"relativeSourceFolder"`
+	Pid                  string `yaml:"pid"`
+	PidShort             string `yaml:"pidShort"`
+	PidPrefix            string `yaml:"pidPrefix"`
+	PidEncoded           string `yaml:"pidEncoded"`
+	Username             string `yaml:"username"`
+}
+
+// Construct the path context of a dataset
+func newFacilityPathContext(pid string, sourceFolder string, relativeSourceFolder string, username string) facilityPathContext {
+	return facilityPathContext{
+		DatasetFolder:        path.Base(sourceFolder),
+		SourceFolder:         sourceFolder,
+		RelativeSourceFolder: relativeSourceFolder,
+		Pid:                  pid,
+		PidShort:             path.Base(pid),
+		PidPrefix:            path.Dir(pid),
+		PidEncoded:           url.PathEscape(pid),
+		Username:             username,
+	}
 }
 
 type facilityPathTemplate = util.TypedTemplate[facilityPathContext]
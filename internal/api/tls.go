@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/logging"
+	"github.com/fsnotify/fsnotify"
+	gin "github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Certificate updates write the files several times in a row
+const certificateReloadDebounce = 500 * time.Millisecond
+
+var clientAuthTypes = map[config.ClientAuth]tls.ClientAuthType{
+	config.ClientAuthNone:     tls.NoClientCert,
+	config.ClientAuthOptional: tls.VerifyClientCertIfGiven,
+	config.ClientAuthRequire:  tls.RequireAndVerifyClientCert,
+}
+
+// TLS settings of the server, with the certificate and the client CAs reloaded when their files change
+type ServerTLS struct {
+	conf    config.TLSConfig
+	current atomic.Pointer[tls.Config]
+}
+
+// Load the certificate and the client CAs. Fails if any of the files is invalid.
+func NewServerTLS(conf config.TLSConfig) (*ServerTLS, error) {
+	s := &ServerTLS{conf: conf}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Config of the server, each connection uses the files loaded last
+func (s *ServerTLS) Config() *tls.Config {
+	return &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return s.current.Load(), nil
+		},
+	}
+}
+
+// Read the files again. If they are invalid, the files loaded before are kept.
+func (s *ServerTLS) Reload() error {
+	cert, err := tls.LoadX509KeyPair(s.conf.CertFile, s.conf.KeyFile)
+	if err != nil {
+		return fmt.Errorf("couldn't load server certificate: %w", err)
+	}
+	minVersion, _ := config.TLSVersion(s.conf.MinVersion)
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   minVersion,
+		ClientAuth:   clientAuthTypes[s.conf.ClientAuth],
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	if s.conf.ClientCaFile != "" {
+		pem, err := os.ReadFile(s.conf.ClientCaFile)
+		if err != nil {
+			return fmt.Errorf("couldn't read client CA file: %w", err)
+		}
+		tlsConfig.ClientCAs = x509.NewCertPool()
+		if !tlsConfig.ClientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file '%s'", s.conf.ClientCaFile)
+		}
+	}
+	s.current.Store(tlsConfig)
+	return nil
+}
+
+// Reload the files when they change or on SIGHUP, until the context is cancelled
+func (s *ServerTLS) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// Watch the directories, as mounted secrets are replaced instead of written to
+	dirs := []string{}
+	for _, file := range []string{s.conf.CertFile, s.conf.KeyFile, s.conf.ClientCaFile} {
+		if dir := filepath.Dir(file); file != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return err
+		}
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		defer watcher.Close()
+		defer signal.Stop(hangup)
+
+		debounce := time.NewTimer(certificateReloadDebounce)
+		debounce.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				s.reloadLogged()
+			case event := <-watcher.Events:
+				if event.Has(fsnotify.Chmod) {
+					continue
+				}
+				debounce.Reset(certificateReloadDebounce)
+			case err := <-watcher.Errors:
+				slog.Warn("error watching TLS certificates", "error", err)
+			case <-debounce.C:
+				s.reloadLogged()
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *ServerTLS) reloadLogged() {
+	if err := s.Reload(); err != nil {
+		slog.Error("couldn't reload TLS certificates, keeping the current certificates", "error", err)
+		return
+	}
+	slog.Info("Reloaded TLS certificates", "certFile", s.conf.CertFile)
+}
+
+// Identify clients by their verified certificate. The identity is logged with the request and added to its span.
+func clientCertificate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+			client := certificateIdentity(c.Request.TLS.VerifiedChains[0][0])
+			trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("tls.client.subject", client))
+			c.Request = c.Request.WithContext(logging.WithClient(c.Request.Context(), client))
+		}
+		c.Next()
+	}
+}
+
+// Name of the client of a certificate: its common name, or else its first DNS or URI name
+func certificateIdentity(cert *x509.Certificate) string {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String()
+	default:
+		return cert.Subject.String()
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/logging"
+	gin "github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// Issue a certificate for name, self-signed if parent is nil
+func issueCert(t *testing.T, name string, parent *testCert) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return testCert{cert: cert, key: key}
+}
+
+func (c testCert) writeFiles(t *testing.T, certFile string, keyFile string) {
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+	if keyFile != "" {
+		der, err := x509.MarshalECPrivateKey(c.key)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+	}
+}
+
+func (c testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+func TestServerTLS(t *testing.T) {
+	dir := t.TempDir()
+	conf := config.TLSConfig{
+		CertFile:     filepath.Join(dir, "cert.pem"),
+		KeyFile:      filepath.Join(dir, "key.pem"),
+		MinVersion:   config.TLSVersion12,
+		ClientCaFile: filepath.Join(dir, "client-ca.pem"),
+		ClientAuth:   config.ClientAuthOptional,
+	}
+	ca := issueCert(t, "test CA", nil)
+	ca.writeFiles(t, conf.ClientCaFile, "")
+	issueCert(t, "server", &ca).writeFiles(t, conf.CertFile, conf.KeyFile)
+	serverTLS, err := NewServerTLS(conf)
+	assert.NoError(t, err)
+
+	r := gin.New()
+	r.Use(clientCertificate())
+	r.GET("/client", func(c *gin.Context) {
+		c.String(http.StatusOK, logging.Client(c.Request.Context()))
+	})
+	server := httptest.NewUnstartedServer(r)
+	server.TLS = serverTLS.Config()
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(certs ...tls.Certificate) (string, error) {
+		tlsConfig := &tls.Config{RootCAs: roots}
+		// send the certificate even if it's not issued by a CA the server asks for
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if len(certs) == 0 {
+				return &tls.Certificate{}, nil
+			}
+			return &certs[0], nil
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		resp, err := client.Get(server.URL + "/client")
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	// clients are identified by a verified certificate, which is optional
+	client, err := get(issueCert(t, "ingestor", &ca).tlsCertificate())
+	assert.NoError(t, err)
+	assert.Equal(t, "ingestor", client)
+	client, err = get()
+	assert.NoError(t, err)
+	assert.Equal(t, "", client)
+	other := issueCert(t, "other CA", nil)
+	_, err = get(issueCert(t, "ingestor", &other).tlsCertificate())
+	assert.Error(t, err)
+
+	// a renewed certificate is used by new connections, invalid files are ignored
+	renewed := issueCert(t, "renewed", &ca)
+	renewed.writeFiles(t, conf.CertFile, conf.KeyFile)
+	assert.NoError(t, serverTLS.Reload())
+	assert.NoError(t, os.WriteFile(conf.KeyFile, []byte("invalid"), 0o600))
+	assert.Error(t, serverTLS.Reload())
+	conn, err := tls.Dial("tcp", server.Listener.Addr().String(), &tls.Config{RootCAs: roots})
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "renewed", conn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+}
@@ -5,25 +5,33 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"net/url"
-	"path"
 	"path/filepath"
 	"reflect"
 	"slices"
+	"time"
 
 	"github.com/SwissOpenEM/globus"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/globusclient"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/logging"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tasks"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/util"
 	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
 	"github.com/gin-gonic/gin"
 )
 
+// How long a used idempotency key maps to the job it created
+const idempotencyKeyTTL = 24 * time.Hour
+
 // check for required group membership.
 // facilitySrcGroupTemplate and facilityDstGroupTemplate are checked against the
 // user's access groups (from Profile.AccessGroups in their user token)
-func checkAuthorization(scicatUser *scicat.User, srcFacility *Facility, dstFacility *Facility, dataset *scicat.ScicatDataset) (bool, string, error) {
+func checkAuthorization(ctx context.Context, scicatUser *scicat.User, srcFacility *Facility, dstFacility *Facility, dataset *scicat.ScicatDataset) (bool, string, error) {
 	// Source access
 	srcContext := accessPathContext{Name: srcFacility.Name}
 	srcAccessPath, err := srcFacility.AccessPath.ExecuteStr(srcContext)
@@ -39,7 +47,7 @@ func checkAuthorization(scicatUser *scicat.User, srcFacility *Facility, dstFacil
 		return false, "", err
 	}
 	if !srcAllowed {
-		slog.Info("User lacks access", "username", scicatUser.Profile.Username, "facility", srcFacility.Name, "accessPath", srcAccessPath, "accessValue", srcAccessValue)
+		slog.InfoContext(ctx, "User lacks access", "username", scicatUser.Profile.Username, "facility", srcFacility.Name, "accessPath", srcAccessPath, "accessValue", srcAccessValue)
 		return false, fmt.Sprintf("No access to facility %v", srcFacility.Name), nil
 	}
 
@@ -58,14 +66,14 @@ func checkAuthorization(scicatUser *scicat.User, srcFacility *Facility, dstFacil
 		return false, "", err
 	}
 	if !dstAllowed {
-		slog.Info("User lacks access", "username", scicatUser.Profile.Username, "facility", dstFacility.Name, "accessPath", dstAccessPath, "accessValue", dstAccessValue)
+		slog.InfoContext(ctx, "User lacks access", "username", scicatUser.Profile.Username, "facility", dstFacility.Name, "accessPath", dstAccessPath, "accessValue", dstAccessValue)
 		return false, fmt.Sprintf("No access to facility %v", dstFacility.Name), nil
 	}
 
 	// Dataset access
 	// TODO also allow the service user to transfer datasets?
 	if !slices.Contains(scicatUser.Profile.AccessGroups, dataset.OwnerGroup) {
-		slog.Info("User lacks access", "username", scicatUser.Profile.Username, "datasetPid", dataset.Pid, "ownerGroup", dataset.OwnerGroup)
+		slog.InfoContext(ctx, "User lacks access", "username", scicatUser.Profile.Username, "datasetPid", dataset.Pid, "ownerGroup", dataset.OwnerGroup)
 		return false, fmt.Sprintf("No access to dataset %v", dataset.Pid), nil
 	}
 
@@ -73,6 +81,9 @@ func checkAuthorization(scicatUser *scicat.User, srcFacility *Facility, dstFacil
 }
 
 func (s ServerHandler) PostTransferTask(ctx context.Context, request PostTransferTaskRequestObject) (PostTransferTaskResponseObject, error) {
+	// set once the transfer is started, to complete the idempotency key
+	var idempotentJobId string
+
 	ginCtx, ok := ctx.(*gin.Context)
 	if !ok {
 		return PostTransferTask500JSONResponse{
@@ -81,13 +92,14 @@ func (s ServerHandler) PostTransferTask(ctx context.Context, request PostTransfe
 	}
 
 	// check facility id's and fetch collection id's
-	srcFacility, ok := s.facilities[request.Params.SourceFacility]
+	facilities := s.getFacilities()
+	srcFacility, ok := facilities[request.Params.SourceFacility]
 	if !ok {
 		return PostTransferTask403JSONResponse{
 			Message: getPointerOrNil("invalid source facility"),
 		}, nil
 	}
-	dstFacility, ok := s.facilities[request.Params.DestFacility]
+	dstFacility, ok := facilities[request.Params.DestFacility]
 	if !ok {
 		return PostTransferTask403JSONResponse{
 			Message: getPointerOrNil("invalid destination facility"),
@@ -128,60 +140,161 @@ func (s ServerHandler) PostTransferTask(ctx context.Context, request PostTransfe
 		}, nil
 	}
 
-	// Get the dataset
-	// TODO make asynchonous
-	scicatService := scicat.ScicatService{
-		Url:   s.scicatUrl,
-		Token: scicatUser.ScicatToken,
+	// Requests retried with the same idempotency key return the earlier job
+	if request.Params.IdempotencyKey != nil && *request.Params.IdempotencyKey != "" {
+		key := scicatUser.Profile.Username + "/" + *request.Params.IdempotencyKey
+		jobId, reserved, err := s.store.ReserveIdempotencyKey(key, idempotencyKeyTTL)
+		if err != nil {
+			return PostTransferTask500JSONResponse{
+				Message: getPointerOrNil("couldn't check idempotency key"),
+				Details: getPointerOrNil(err.Error()),
+			}, nil
+		}
+		if !reserved {
+			if jobId == "" {
+				return PostTransferTask409JSONResponse{
+					Message: getPointerOrNil("a request with the same idempotency key is still being processed"),
+				}, nil
+			}
+			if request.Params.Async != nil && *request.Params.Async {
+				return transferAccepted(ginCtx, jobId), nil
+			}
+			return PostTransferTask200JSONResponse{
+				JobId: jobId,
+			}, nil
+		}
+		defer func() {
+			if idempotentJobId == "" {
+				_ = s.store.ReleaseIdempotencyKey(key)
+			} else if err := s.store.CompleteIdempotencyKey(key, idempotentJobId); err != nil {
+				slog.ErrorContext(ctx, "couldn't store idempotency key", "jobId", idempotentJobId, "error", err)
+			}
+		}()
+	}
+
+	// Check that the queue is available
+	if s.taskPool.IsQueueSizeLimited() {
+		s.addTaskMutex.Lock()
+		defer s.addTaskMutex.Unlock()
+		if !s.taskPool.CanSubmitJob() {
+			return PostTransferTask503JSONResponse{
+				Message: getPointerOrNil("the task queue is currently full, try again later..."),
+			}, nil
+		}
+	}
+
+	// the gin context is reused after the request, so the submission uses the request's context
+	reqCtx := ginCtx.Request.Context()
+	submit := func(submitCtx context.Context, transfer store.Transfer) (string, error) {
+		return s.submitGlobusTransfer(submitCtx, srcFacility, dstFacility, transfer.TransferParams)
+	}
+
+	transfer, reqErr := s.prepareTransfer(reqCtx, scicatUser, srcFacility, dstFacility, request)
+	if reqErr != nil {
+		return reqErr.response(), nil
+	}
+
+	// a client disconnecting after the job was created must not leave the transfer half submitted
+	submissionCtx := context.WithoutCancel(reqCtx)
+
+	// The remaining steps are recorded on the job, so they can run after responding
+	if request.Params.Async != nil && *request.Params.Async {
+		transfer, err := s.taskPool.CreateTransferJob(submissionCtx, transfer)
+		if err != nil {
+			return submissionErrorResponse(err), nil
+		}
+		idempotentJobId = transfer.ScicatJobId
+		s.taskPool.CompleteSubmissionAsync(submissionCtx, transfer, submit)
+		return transferAccepted(ginCtx, transfer.ScicatJobId), nil
 	}
 
-	dataset, err := scicatService.GetDataset(request.Params.ScicatPid)
+	transfer, err := s.taskPool.SubmitTransfer(submissionCtx, transfer, submit)
 	if err != nil {
-		slog.Error("error fetching dataset from scicat", "error", err)
+		return submissionErrorResponse(err), nil
+	}
+	idempotentJobId = transfer.ScicatJobId
+
+	// return response
+	return PostTransferTask200JSONResponse{
+		JobId: transfer.ScicatJobId,
+	}, nil
+}
+
+// Response to a transfer that is submitted in the background, with the path of its status
+func transferAccepted(ginCtx *gin.Context, jobId string) PostTransferTaskResponseObject {
+	return PostTransferTask202JSONResponse{
+		JobId:     jobId,
+		StatusUrl: ginCtx.Request.URL.JoinPath(url.PathEscape(jobId)).Path,
+	}
+}
+
+// A transfer request that was rejected while preparing it
+type transferRequestError struct {
+	statusCode int
+	message    string
+	details    string
+}
+
+func (e *transferRequestError) Error() string {
+	if e.details == "" {
+		return e.message
+	}
+	return e.message + ": " + e.details
+}
+
+func (e *transferRequestError) response() PostTransferTaskResponseObject {
+	switch e.statusCode {
+	case http.StatusBadRequest:
+		return PostTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil(e.message),
+				Details: getPointerOrNil(e.details),
+			},
+		}
+	case http.StatusUnauthorized:
+		return PostTransferTask401JSONResponse{
+			Message: getPointerOrNil(e.message),
+			Details: getPointerOrNil(e.details),
+		}
+	default:
+		return PostTransferTask500JSONResponse{
+			Message: getPointerOrNil(e.message),
+			Details: getPointerOrNil(e.details),
+		}
+	}
+}
+
+// Fetch the dataset, check that the user may transfer it, and resolve the paths of the transfer
+func (s ServerHandler) prepareTransfer(ctx context.Context, scicatUser scicat.User, srcFacility Facility, dstFacility Facility, request PostTransferTaskRequestObject) (store.Transfer, *transferRequestError) {
+	// Get the dataset
+	dataset, err := s.scicat.GetDataset(ctx, scicatUser.ScicatToken, request.Params.ScicatPid)
+	if err != nil {
+		slog.ErrorContext(ctx, "error fetching dataset from scicat", "error", err)
 
 		var httpErr *scicat.HttpError
 		if errors.As(err, &httpErr) {
 			switch httpErr.StatusCode {
 			case 400, 401, 403:
-				return PostTransferTask400JSONResponse{
-					GeneralErrorResponseJSONResponse{
-						Message: getPointerOrNil(httpErr.Message),
-						Details: getPointerOrNil(httpErr.Details),
-					},
-				}, nil
+				return store.Transfer{}, &transferRequestError{http.StatusBadRequest, httpErr.Message, httpErr.Details}
 			default:
-				return PostTransferTask500JSONResponse{
-					Message: getPointerOrNil(httpErr.Message),
-					Details: getPointerOrNil(httpErr.Details),
-				}, nil
+				return store.Transfer{}, &transferRequestError{http.StatusInternalServerError, httpErr.Message, httpErr.Details}
 			}
 		}
-		var detailedResponse scicat.DetailedError
+		var detailedResponse *scicat.DetailedError
 		if errors.As(err, &detailedResponse) {
-			return PostTransferTask500JSONResponse{
-				Message: getPointerOrNil(detailedResponse.Error()),
-				Details: getPointerOrNil(detailedResponse.Details),
-			}, nil
+			return store.Transfer{}, &transferRequestError{http.StatusInternalServerError, detailedResponse.Error(), detailedResponse.Details}
 		}
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("unable to fetch dataset " + request.Params.ScicatPid),
-		}, nil
+		return store.Transfer{}, &transferRequestError{http.StatusInternalServerError, "unable to fetch dataset " + request.Params.ScicatPid, ""}
 	}
 
-	ok, msg, err := checkAuthorization(&scicatUser, &srcFacility, &dstFacility, &dataset)
+	ok, msg, err := checkAuthorization(ctx, &scicatUser, &srcFacility, &dstFacility, &dataset)
 	if err != nil {
-		slog.Error("checkAuthorization returned an error", "error", err)
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("you don't have the required access groups to request this transfer"),
-			Details: getPointerOrNil(msg),
-		}, nil
+		slog.ErrorContext(ctx, "checkAuthorization returned an error", "error", err)
+		return store.Transfer{}, &transferRequestError{http.StatusInternalServerError, "you don't have the required access groups to request this transfer", msg}
 	}
 	if !ok {
-		slog.Error("user not authorized", "message", msg)
-		return PostTransferTask401JSONResponse{
-			Message: getPointerOrNil("you don't have the required access groups to request this transfer"),
-			Details: getPointerOrNil(msg),
-		}, nil
+		slog.ErrorContext(ctx, "user not authorized", "message", msg)
+		return store.Transfer{}, &transferRequestError{http.StatusUnauthorized, "you don't have the required access groups to request this transfer", msg}
 	}
 
 	// Check that the dataset is within the globus collection on the source
@@ -190,122 +303,113 @@ func (s ServerHandler) PostTransferTask(ctx context.Context, request PostTransfe
 	if rootPath != "" {
 		relPath, err := filepath.Rel(rootPath, dataset.SourceFolder)
 		if err != nil {
-			return PostTransferTask400JSONResponse{
-				GeneralErrorResponseJSONResponse{
-					Message: getPointerOrNil("dataset is not accessible from globus"),
-					Details: getPointerOrNil(fmt.Sprintf("sourceFolder: %v", dataset.SourceFolder)),
-				},
-			}, nil
+			return store.Transfer{}, &transferRequestError{http.StatusBadRequest, "dataset is not accessible from globus", fmt.Sprintf("sourceFolder: %v", dataset.SourceFolder)}
 		}
 		relativeSourceFolder = relPath
 	}
 
 	// Prepare globus parameters
 
-	params := facilityPathContext{
-		DatasetFolder:        path.Base(dataset.SourceFolder),
-		SourceFolder:         dataset.SourceFolder,
-		RelativeSourceFolder: relativeSourceFolder,
-		Pid:                  request.Params.ScicatPid,
-		PidShort:             path.Base(request.Params.ScicatPid),
-		PidPrefix:            path.Dir(request.Params.ScicatPid),
-		PidEncoded:           url.PathEscape(request.Params.ScicatPid),
-		Username:             scicatUser.Profile.Username,
-	}
+	params := newFacilityPathContext(request.Params.ScicatPid, dataset.SourceFolder, relativeSourceFolder, scicatUser.Profile.Username)
 
 	srcPath, err := srcFacility.SourcePath.ExecuteStr(params)
 	if err != nil {
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("couldn't template source folder for the transfer"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
+		return store.Transfer{}, &transferRequestError{http.StatusInternalServerError, "couldn't template source folder for the transfer", err.Error()}
 	}
 
 	destPath, err := dstFacility.DestinationPath.ExecuteStr(params)
 	if err != nil {
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("couldn't template destination folder for the transfer"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
+		return store.Transfer{}, &transferRequestError{http.StatusInternalServerError, "couldn't template destination folder for the transfer", err.Error()}
 	}
 
-	// Check that the queue is available
-	if s.taskPool.IsQueueSizeLimited() {
-		s.addTaskMutex.Lock()
-		defer s.addTaskMutex.Unlock()
-		if !s.taskPool.CanSubmitJob() {
-			return PostTransferTask503JSONResponse{
-				Message: getPointerOrNil("the task queue is currently full, try again later..."),
-			}, nil
-		}
-	}
+	// Default backwards compatible behavior is to archive
+	var archive = request.Params.AutoArchive == nil || *request.Params.AutoArchive
 
 	// Prepare file list
 	// TODO: could the file list be prepared from `scicat.GetOrigDatablocks`?
-	var globusResult globus.TransferResult
+	var fileList []jobs.TransferFile
 	if request.Body != nil && request.Body.FileList != nil {
-		// use filelist
-		paths := make([]string, len(*request.Body.FileList))
-		isSymlinks := make([]bool, len(*request.Body.FileList))
+		fileList = make([]jobs.TransferFile, len(*request.Body.FileList))
 		for i, file := range *request.Body.FileList {
+			fileList[i] = jobs.TransferFile{Path: file.Path, IsSymlink: file.IsSymlink}
+		}
+	}
+
+	return store.Transfer{
+		DatasetPids: []string{request.Params.ScicatPid},
+		ArchivalJobInfo: jobs.ArchivalJobInfo{
+			OwnerUser:    scicatUser.Profile.Username,
+			OwnerGroup:   dataset.OwnerGroup,
+			AutoArchive:  archive,
+			ContactEmail: scicatUser.Profile.Email,
+		},
+		TransferParams: jobs.TransferParams{
+			SourceFacility:      srcFacility.Name,
+			DestinationFacility: dstFacility.Name,
+			SourcePath:          srcPath,
+			DestinationPath:     destPath,
+			FileList:            fileList,
+		},
+		ProxyVersion: s.version,
+		RequestId:    logging.RequestId(ctx),
+		TraceParent:  tracing.TraceParent(ctx),
+	}, nil
+}
+
+// Request the transfer from globus, returns the globus task id
+func (s ServerHandler) submitGlobusTransfer(ctx context.Context, srcFacility Facility, dstFacility Facility, params jobs.TransferParams) (string, error) {
+	var globusResult globus.TransferResult
+	var err error
+	if params.FileList != nil {
+		// use filelist
+		paths := make([]string, len(params.FileList))
+		isSymlinks := make([]bool, len(params.FileList))
+		for i, file := range params.FileList {
 			paths[i] = file.Path
 			isSymlinks[i] = file.IsSymlink
 		}
-		slog.Info("Submitting transfer task to globus with filelist", "sourceEndpoint", srcFacility.Collection, "sourcePath", srcPath, "destEndpoint", dstFacility.Collection, "destPath", destPath, "fileCount", len(paths))
-		globusResult, err = s.globusClient.TransferFileList(srcFacility.Collection, srcPath, dstFacility.Collection, destPath, paths, isSymlinks, true)
+		slog.InfoContext(ctx, "Submitting transfer task to globus with filelist", "sourceEndpoint", srcFacility.Collection, "sourcePath", params.SourcePath, "destEndpoint", dstFacility.Collection, "destPath", params.DestinationPath, "fileCount", len(paths))
+		globusResult, err = globusclient.TransferFileList(ctx, s.globusClient, srcFacility.Collection, params.SourcePath, dstFacility.Collection, params.DestinationPath, paths, isSymlinks)
 	} else {
 		// sync folders through globus
-		slog.Info("Submitting transfer task to globus", "sourceEndpoint", srcFacility.Collection, "sourcePath", srcPath, "destEndpoint", dstFacility.Collection, "destPath", destPath)
-		globusResult, err = s.globusClient.TransferFolderSync(srcFacility.Collection, srcPath, dstFacility.Collection, destPath, true)
+		slog.InfoContext(ctx, "Submitting transfer task to globus", "sourceEndpoint", srcFacility.Collection, "sourcePath", params.SourcePath, "destEndpoint", dstFacility.Collection, "destPath", params.DestinationPath)
+		globusResult, err = globusclient.TransferFolderSync(ctx, s.globusClient, srcFacility.Collection, params.SourcePath, dstFacility.Collection, params.DestinationPath)
 	}
+	return globusResult.TaskId, err
+}
 
-	if err != nil {
+// Map a failed submission step to the response of the transfer request
+func submissionErrorResponse(err error) PostTransferTaskResponseObject {
+	var submissionErr *tasks.SubmissionError
+	if !errors.As(err, &submissionErr) {
+		return PostTransferTask500JSONResponse{
+			Message: getPointerOrNil("transfer submission failed"),
+			Details: getPointerOrNil(err.Error()),
+		}
+	}
+	details := submissionErr.Err.Error()
+	if submissionErr.ScicatJobId != "" {
+		details = fmt.Sprintf("job '%s': %s", submissionErr.ScicatJobId, details)
+	}
+	switch submissionErr.Step {
+	case tasks.StepSubmitGlobus:
 		return PostTransferTask400JSONResponse{
 			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
 				Message: getPointerOrNil("can't request globus transfer"),
-				Details: getPointerOrNil(err.Error()),
+				Details: getPointerOrNil(details),
 			},
-		}, nil
-	}
-
-	// Log in to globus
-	serviceUserToken, err := s.scicatServiceUser.GetToken()
-	if err != nil {
-		_, _ = s.globusClient.TransferCancelTaskByID(globusResult.TaskId) // attempt to cancel transfer
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("service user login failed"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
-	}
-
-	// request the transfer
-	// TODO: replace the service user token with the current user's token if it becomes possible to create the scicatJob as one's own user
-	//   , which will happen once the required changes are merged into BE SciCat. If the changes will still not allow this, just
-	//   remove this TODO.
-	scicatJob, err := tasks.CreateGlobusTransferScicatJob(s.scicatUrl, serviceUserToken, dataset.OwnerGroup, params.Pid, "")
-	if err != nil {
-		_, _ = s.globusClient.TransferCancelTaskByID(globusResult.TaskId) // attempt to cancel transfer
+		}
+	case tasks.StepCreateJob:
 		return PostTransferTask500JSONResponse{
 			Message: getPointerOrNil("failed creating transfer job in SciCat"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
-	}
-
-	// Default backwards compatible behavior is to archive
-	var archive = request.Params.AutoArchive == nil || *request.Params.AutoArchive
-
-	archivalJobInfo := tasks.ArchivalJobInfo{
-		OwnerUser:    scicatUser.Profile.Username,
-		OwnerGroup:   dataset.OwnerGroup,
-		AutoArchive:  archive,
-		ContactEmail: scicatUser.Profile.Email,
+			Details: getPointerOrNil(details),
+		}
+	default:
+		return PostTransferTask500JSONResponse{
+			Message: getPointerOrNil("failed attaching the globus transfer to the SciCat job"),
+			Details: getPointerOrNil(details),
+		}
 	}
-	s.taskPool.AddTransferTask(globusResult.TaskId, request.Params.ScicatPid, scicatJob.ID, archivalJobInfo)
-
-	// return response
-	return PostTransferTask200JSONResponse{
-		JobId: scicatJob.ID,
-	}, nil
 }
 
 func (s ServerHandler) DeleteTransferTask(ctx context.Context, req DeleteTransferTaskRequestObject) (DeleteTransferTaskResponseObject, error) {
@@ -340,7 +444,7 @@ func (s ServerHandler) DeleteTransferTask(ctx context.Context, req DeleteTransfe
 		}, nil
 	}
 
-	job, err := jobs.GetJobById(s.scicatUrl, serviceToken, req.ScicatJobId)
+	job, err := s.scicat.GetJobById(ginCtx.Request.Context(), serviceToken, req.ScicatJobId)
 	if err != nil {
 		return DeleteTransferTask400JSONResponse{
 			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
@@ -357,16 +461,14 @@ func (s ServerHandler) DeleteTransferTask(ctx context.Context, req DeleteTransfe
 	}
 
 	if req.Params.Delete != nil && *req.Params.Delete {
-		err = s.taskPool.DeleteTransferTask(req.ScicatJobId)
+		err = s.taskPool.DeleteTransferTask(ginCtx.Request.Context(), req.ScicatJobId)
 	} else {
 		err = s.taskPool.CancelTransferTask(req.ScicatJobId)
 	}
 
 	if err != nil {
 		JobNotExistErr := &tasks.JobNotExistError{}
-		JobDelete400Err := &tasks.JobDeleteNotExist{}
-		JobNotFoundErr := &jobs.JobNotFoundErr{}
-		if errors.As(err, &JobNotExistErr) || errors.As(err, &JobDelete400Err) || errors.As(err, &JobNotFoundErr) {
+		if errors.As(err, &JobNotExistErr) || errors.Is(err, scicat.ErrJobNotFound) {
 			return DeleteTransferTask400JSONResponse{GeneralErrorResponseJSONResponse{
 				Message: getPointerOrNil("the requested job does not exist or is already finished or cancelled"),
 			}}, nil
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+)
+
+// Whether the user may see a transfer owned by the given user and group
+func (s ServerHandler) canSeeTransfer(user scicat.User, ownerUser string, ownerGroup string) bool {
+	return ownerUser == user.Profile.Username || slices.Contains(user.Profile.AccessGroups, ownerGroup) || s.isAdmin(user)
+}
+
+// Convert a transfer of the store to its API representation
+func transferItemFromStore(transfer store.Transfer) TransferItem {
+	return TransferItem{
+		TransferId:          transfer.ScicatJobId,
+		Status:              TransferItemStatus(transfer.Status),
+		GlobusTaskId:        getPointerOrNil(transfer.GlobusTaskId),
+		DatasetPids:         &transfer.DatasetPids,
+		SourceFacility:      getPointerOrNil(transfer.SourceFacility),
+		DestinationFacility: getPointerOrNil(transfer.DestinationFacility),
+		OwnerUser:           getPointerOrNil(transfer.OwnerUser),
+		OwnerGroup:          getPointerOrNil(transfer.OwnerGroup),
+		CreatedAt:           &transfer.CreatedAt,
+		UpdatedAt:           &transfer.UpdatedAt,
+	}
+}
+
+// Convert a transfer job of SciCat to its API representation
+func transferItemFromJob(job jobs.ScicatJob) TransferItem {
+	pids := make([]string, len(job.JobParams.DatasetList))
+	for i, dataset := range job.JobParams.DatasetList {
+		pids[i] = dataset.Pid
+	}
+	message := job.StatusMessage
+	if job.JobResultObject.Error != "" {
+		message = job.JobResultObject.Error
+	}
+	bytesTransferred := int(job.JobResultObject.BytesTransferred)
+	filesTransferred := int(job.JobResultObject.FilesTransferred)
+	filesTotal := int(job.JobResultObject.FilesTotal)
+	item := TransferItem{
+		TransferId:       job.ID,
+		Status:           TransferItemStatus(job.JobResultObject.Status),
+		Message:          getPointerOrNil(message),
+		BytesTransferred: &bytesTransferred,
+		FilesTransferred: &filesTransferred,
+		FilesTotal:       &filesTotal,
+		GlobusTaskId:     getPointerOrNil(job.JobResultObject.GlobusTaskId),
+		DatasetPids:      &pids,
+		OwnerUser:        getPointerOrNil(job.OwnerUser),
+		OwnerGroup:       getPointerOrNil(job.OwnerGroup),
+		CreatedAt:        &job.CreatedAt,
+		UpdatedAt:        &job.UpdatedAt,
+	}
+	if job.JobParams.TransferParams != nil {
+		item.SourceFacility = getPointerOrNil(job.JobParams.TransferParams.SourceFacility)
+		item.DestinationFacility = getPointerOrNil(job.JobParams.TransferParams.DestinationFacility)
+	}
+	return item
+}
+
+// Sort the transfers newest first and return the requested page
+func pageTransfers(transfers []store.Transfer, offset int, limit int) []store.Transfer {
+	slices.SortFunc(transfers, func(a, b store.Transfer) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if offset >= len(transfers) {
+		return []store.Transfer{}
+	}
+	end := min(offset+limit, len(transfers))
+	return transfers[offset:end]
+}
+
+func (s ServerHandler) ListTransfers(ctx context.Context, request ListTransfersRequestObject) (ListTransfersResponseObject, error) {
+	user, err := getScicatUser(ctx)
+	if err != nil {
+		return ListTransfers500JSONResponse{
+			Message: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	transfers, err := s.store.ListTransfers(func(transfer store.Transfer) bool {
+		if request.Params.Status != nil && string(transfer.Status) != *request.Params.Status {
+			return false
+		}
+		return s.canSeeTransfer(user, transfer.OwnerUser, transfer.OwnerGroup)
+	})
+	if err != nil {
+		return ListTransfers500JSONResponse{
+			Message: getPointerOrNil("couldn't list transfers"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	offset, limit := 0, 100
+	if request.Params.Offset != nil {
+		offset = *request.Params.Offset
+	}
+	if request.Params.Limit != nil {
+		limit = *request.Params.Limit
+	}
+	page := pageTransfers(transfers, offset, limit)
+	items := make([]TransferItem, len(page))
+	for i, transfer := range page {
+		items[i] = transferItemFromStore(transfer)
+	}
+	return ListTransfers200JSONResponse{
+		Transfers: items,
+		Total:     len(transfers),
+	}, nil
+}
+
+func (s ServerHandler) GetTransferTask(ctx context.Context, request GetTransferTaskRequestObject) (GetTransferTaskResponseObject, error) {
+	user, err := getScicatUser(ctx)
+	if err != nil {
+		return GetTransferTask500JSONResponse{
+			Message: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	serviceToken, err := s.scicatServiceUser.GetToken()
+	if err != nil {
+		return GetTransferTask500JSONResponse{
+			Message: getPointerOrNil("couldn't access SciCat"),
+			Details: getPointerOrNil("SciCat token renewal failed: " + err.Error()),
+		}, nil
+	}
+
+	job, err := s.scicat.GetJobById(ctx, serviceToken, request.ScicatJobId)
+	if err != nil {
+		if errors.Is(err, scicat.ErrJobNotFound) {
+			return GetTransferTask404JSONResponse{
+				Message: getPointerOrNil("the requested job does not exist"),
+			}, nil
+		}
+		return GetTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("failed to request job from SciCat"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+	if job.Type != "globus_transfer_job" {
+		return GetTransferTask404JSONResponse{
+			Message: getPointerOrNil("the requested job is not a transfer job"),
+		}, nil
+	}
+	if !s.canSeeTransfer(user, job.OwnerUser, job.OwnerGroup) {
+		return GetTransferTask403JSONResponse{
+			Message: getPointerOrNil("you don't have the right to see this job"),
+		}, nil
+	}
+
+	return GetTransferTask200JSONResponse(transferItemFromJob(job)), nil
+}
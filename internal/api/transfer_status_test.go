@@ -0,0 +1,29 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageTransfers(t *testing.T) {
+	now := time.Now()
+	transfers := []store.Transfer{
+		{ScicatJobId: "old", CreatedAt: now.Add(-2 * time.Hour)},
+		{ScicatJobId: "new", CreatedAt: now},
+		{ScicatJobId: "middle", CreatedAt: now.Add(-time.Hour)},
+	}
+	ids := func(transfers []store.Transfer) []string {
+		result := []string{}
+		for _, transfer := range transfers {
+			result = append(result, transfer.ScicatJobId)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"new", "middle", "old"}, ids(pageTransfers(transfers, 0, 100)))
+	assert.Equal(t, []string{"middle"}, ids(pageTransfers(transfers, 1, 1)))
+	assert.Equal(t, []string{}, ids(pageTransfers(transfers, 3, 1)))
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/serviceuser"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tasks"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// SciCat server with a dataset "pid1" and the jobs created through it
+type fakeScicat struct {
+	*httptest.Server
+	jobs  map[string]*jobs.ScicatJob
+	mutex sync.Mutex
+}
+
+func newFakeScicat(t *testing.T) *fakeScicat {
+	f := &fakeScicat{jobs: map[string]*jobs.ScicatJob{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v3/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"access_token":"token","expires_in":3600,"created":"%s"}`, time.Now().Format(time.RFC3339))
+	})
+	mux.HandleFunc("GET /api/v3/users/my/identity", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /api/v3/datasets/{pid}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("pid") != "pid1" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"forbidden"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"pid":"pid1","sourceFolder":"/data/pid1","ownerGroup":"group1"}`))
+	})
+	mux.HandleFunc("POST /api/v4/jobs", func(w http.ResponseWriter, r *http.Request) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		job := &jobs.ScicatJob{}
+		_ = json.NewDecoder(r.Body).Decode(job)
+		job.ID = fmt.Sprintf("job%d", len(f.jobs)+1)
+		f.jobs[job.ID] = job
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(job)
+	})
+	mux.HandleFunc("GET /api/v4/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		_ = json.NewEncoder(w).Encode(f.jobs[r.PathValue("id")])
+	})
+	mux.HandleFunc("PATCH /api/v4/jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		job := f.jobs[r.PathValue("id")]
+		_ = json.NewDecoder(r.Body).Decode(job)
+		_ = json.NewEncoder(w).Encode(job)
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeScicat) jobCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.jobs)
+}
+
+func TestPostTransferTaskAsync(t *testing.T) {
+	fake := newFakeScicat(t)
+	st, err := store.Open(filepath.Join(t.TempDir(), "state.db"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = st.Close() })
+
+	scicatClient := scicat.NewClient(fake.URL+"/", config.NewScicatConfig(), http.DefaultTransport)
+	serviceUser, err := serviceuser.CreateServiceUser(fake.URL+"/", "service", "password", fake.Client())
+	assert.NoError(t, err)
+	outbox, err := tasks.NewStoreOutbox(st, tasks.ScicatJobUpdateSender(scicatClient, serviceUser))
+	assert.NoError(t, err)
+	compensations, err := tasks.NewCompensationQueue(st)
+	assert.NoError(t, err)
+	// globus rejects every submission
+	globusClient := globus.HttpClientToGlobusClient(&http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("globus unavailable")
+	})})
+	taskPool := tasks.CreateTaskPool(scicatClient, globusClient, serviceUser, outbox, compensations, st, 1, 0, 1, 1)
+
+	facilityConfigs := []config.FacilityConfig{*config.NewFacilityConfig(), *config.NewFacilityConfig()}
+	facilityConfigs[0].Name, facilityConfigs[0].Collection = "src", "src-collection"
+	facilityConfigs[1].Name, facilityConfigs[1].Collection = "dst", "dst-collection"
+	facilities, err := NewFacilities(facilityConfigs)
+	assert.NoError(t, err)
+	handler, err := NewServerHandler("test", NewReadiness(), globusClient, scicatClient, serviceUser, &facilities, taskPool, st, nil, nil, nil)
+	assert.NoError(t, err)
+
+	user := scicat.User{ScicatToken: "token"}
+	user.Profile.Username = "user"
+	user.Profile.AccessGroups = []string{"src", "dst", "group1"}
+	userContext := func(method string, path string) *gin.Context {
+		ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ginCtx.Request = httptest.NewRequest(method, path, nil)
+		ginCtx.Set("scicatUser", user)
+		return ginCtx
+	}
+	post := func(pid string) PostTransferTaskResponseObject {
+		async := true
+		key := "key-" + pid
+		response, err := handler.PostTransferTask(userContext(http.MethodPost, "/transfer"), PostTransferTaskRequestObject{
+			Params: PostTransferTaskParams{SourceFacility: "src", DestFacility: "dst", ScicatPid: pid, Async: &async, IdempotencyKey: &key},
+		})
+		assert.NoError(t, err)
+		return response
+	}
+
+	// the dataset is checked before responding
+	_, ok := post("unknown").(PostTransferTask400JSONResponse)
+	assert.True(t, ok)
+	assert.Equal(t, 0, fake.jobCount())
+
+	response := post("pid1")
+	assert.Equal(t, PostTransferTask202JSONResponse{JobId: "job1", StatusUrl: "/transfer/job1"}, response)
+
+	// a retried request returns the same job
+	assert.Equal(t, response, post("pid1"))
+	assert.Equal(t, 1, fake.jobCount())
+
+	// the failed globus submission is reported on the job
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	assert.NoError(t, taskPool.WaitForSubmissions(ctx))
+	assert.NoError(t, outbox.Flush(ctx))
+
+	status, err := handler.GetTransferTask(userContext(http.MethodGet, "/transfer/job1"), GetTransferTaskRequestObject{ScicatJobId: "job1"})
+	assert.NoError(t, err)
+	item, ok := status.(GetTransferTask200JSONResponse)
+	assert.True(t, ok)
+	assert.Equal(t, SubmissionFailed, item.Status)
+	assert.Contains(t, *item.Message, "globus unavailable")
+	assert.Equal(t, "submit_globus", fake.jobs["job1"].JobResultObject.FailedStep)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"fmt"
+	"path"
+
+	config "github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/goccy/go-yaml"
+)
+
+// A problem found when validating the config
+type ConfigProblem struct {
+	// Facility the problem is about, or empty for problems of the whole config
+	Facility string
+	Message  string
+}
+
+func (p ConfigProblem) String() string {
+	if p.Facility == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("facility %s: %s", p.Facility, p.Message)
+}
+
+// Datasets the path templates are rendered against when no samples are given
+var defaultSampleContexts = []facilityPathContext{
+	newFacilityPathContext("20.500.12269/0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d", "/data/p12345/raw/2024-05-17_run001", "data/p12345/raw/2024-05-17_run001", "jdoe"),
+	newFacilityPathContext("PID.SAMPLE.PREFIX0001/dataset with spaces", "/gpfs/beamline/dataset with spaces", "/gpfs/beamline/dataset with spaces", "first.last@example.com"),
+}
+
+// Read sample path contexts from a yaml list. Fields derived from the pid or the
+// source folder can be omitted.
+func readSampleContexts(contents []byte) ([]facilityPathContext, error) {
+	var samples []facilityPathContext
+	if err := yaml.Unmarshal(contents, &samples); err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no sample datasets defined")
+	}
+	for i, sample := range samples {
+		derived := newFacilityPathContext(sample.Pid, sample.SourceFolder, sample.SourceFolder, sample.Username)
+		if sample.DatasetFolder == "" {
+			samples[i].DatasetFolder = derived.DatasetFolder
+		}
+		if sample.RelativeSourceFolder == "" {
+			samples[i].RelativeSourceFolder = derived.RelativeSourceFolder
+		}
+		if sample.PidShort == "" {
+			samples[i].PidShort = derived.PidShort
+		}
+		if sample.PidPrefix == "" {
+			samples[i].PidPrefix = derived.PidPrefix
+		}
+		if sample.PidEncoded == "" {
+			samples[i].PidEncoded = derived.PidEncoded
+		}
+	}
+	return samples, nil
+}
+
+// Check the facilities of a config without contacting any service. Every template
+// is compiled and rendered, the path templates against each sample dataset. The
+// samples are a yaml list of path contexts; the built-in samples are used if nil.
+func ValidateConfig(conf config.Config, samples []byte) ([]ConfigProblem, error) {
+	sampleContexts := defaultSampleContexts
+	if samples != nil {
+		var err error
+		sampleContexts, err = readSampleContexts(samples)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sample datasets: %w", err)
+		}
+	}
+
+	problems := []ConfigProblem{}
+	report := func(facility string, format string, args ...any) {
+		problems = append(problems, ConfigProblem{Facility: facility, Message: fmt.Sprintf(format, args...)})
+	}
+
+	sources, destinations := 0, 0
+	collections := map[string]string{}
+	for _, facConf := range conf.Facilities {
+		name := facConf.Name
+		isSource, isDestination := false, false
+		switch facConf.Direction {
+		case config.DirectionSource:
+			isSource = true
+		case config.DirectionDestination:
+			isDestination = true
+		case config.DirectionBoth:
+			isSource, isDestination = true, true
+		default:
+			report(name, "invalid direction '%s', expected '%s', '%s' or '%s'", facConf.Direction, config.DirectionSource, config.DirectionDestination, config.DirectionBoth)
+		}
+		if isSource {
+			sources++
+		}
+		if isDestination {
+			destinations++
+		}
+
+		if facConf.Collection == "" {
+			report(name, "missing collection")
+		} else if other, exists := collections[facConf.Collection]; exists {
+			report(name, "collection %s is also used by facility %s", facConf.Collection, other)
+		} else {
+			collections[facConf.Collection] = name
+		}
+
+		single := config.Config{Facilities: []config.FacilityConfig{facConf}}
+		if scopes, err := single.GetGlobusScopes(); err != nil {
+			report(name, "scopes: %v", err)
+		} else {
+			for _, scope := range scopes {
+				if scope == "" {
+					report(name, "scopes: a scope renders to an empty string")
+				}
+			}
+		}
+
+		facility, err := NewFacility(facConf)
+		if err != nil {
+			report(name, "%v", err)
+			continue
+		}
+		accessContext := accessPathContext{Name: name}
+		checkAccessTemplate := func(field string, tpl *accessPathTemplate) {
+			if value, err := tpl.ExecuteStr(accessContext); err != nil {
+				report(name, "%s: %v", field, err)
+			} else if value == "" {
+				report(name, "%s renders to an empty string", field)
+			}
+		}
+		checkAccessTemplate("accessPath", facility.AccessPath)
+		checkAccessTemplate("accessValue", facility.AccessValue)
+
+		// Only the path of the direction the facility is used in is rendered
+		checkPathTemplate := func(field string, tpl *facilityPathTemplate) {
+			for _, sample := range sampleContexts {
+				if value, err := tpl.ExecuteStr(sample); err != nil {
+					report(name, "%s: %v", field, err)
+					return
+				} else if value == "" || path.Clean(value) == "/" {
+					report(name, "%s renders to '%s' for sample dataset %s", field, value, sample.Pid)
+					return
+				}
+			}
+		}
+		if isSource {
+			checkPathTemplate("sourcePath", facility.SourcePath)
+		}
+		if isDestination {
+			checkPathTemplate("destinationPath", facility.DestinationPath)
+		}
+	}
+
+	// A transfer needs a source and a destination facility
+	for _, facConf := range conf.Facilities {
+		if facConf.Direction == config.DirectionSource && destinations == 0 {
+			report(facConf.Name, "can only be a source, but no facility can be a destination")
+		}
+		if facConf.Direction == config.DirectionDestination && sources == 0 {
+			report(facConf.Name, "can only be a destination, but no facility can be a source")
+		}
+	}
+	if sources == 0 && destinations == 0 {
+		report("", "no facility can be used for transfers")
+	}
+
+	return problems, nil
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func problemStrings(problems []ConfigProblem) []string {
+	messages := []string{}
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+	return messages
+}
+
+func TestValidateConfig(t *testing.T) {
+	conf, err := config.ReadConfigFromBytes([]byte(`
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "Source"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+    direction: SRC
+    sourcePath: "/{{ .PidShort }}/{{ .DatasetFolder }}"
+  - name: "Destination"
+    collection: bbbb2222-33cc-ff55-ee6e-777778888888
+    direction: DST
+    destinationPath: "/archive/{{ .Username }}/{{ .PidShort }}"
+`))
+	assert.Nil(t, err)
+	problems, err := ValidateConfig(conf, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, problems)
+
+	conf.Facilities[0].SourcePath = "/{{ .Pidd }}"
+	conf.Facilities[0].AccessValue = "{{ .Name"
+	conf.Facilities[1].Collection = conf.Facilities[0].Collection
+	conf.Facilities[1].DestinationPath = "{{ .RelativeSourceFolder }}"
+	conf.Facilities[1].Direction = "DEST"
+	problems, err = ValidateConfig(conf, []byte(`
+- pid: "20.500.12269/abc"
+  sourceFolder: "/data/abc"
+  relativeSourceFolder: ""
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"facility Source: accessValue: template: :1: unclosed action",
+		"facility Destination: invalid direction 'DEST', expected 'SRC', 'DST' or 'BOTH'",
+		"facility Destination: collection aaaa1111-22bb-cc44-dd5e-666667777777 is also used by facility Source",
+		"facility Source: can only be a source, but no facility can be a destination",
+	}, problemStrings(problems))
+
+	// path templates are only rendered for the direction a facility is used in
+	conf.Facilities[0].AccessValue = ""
+	conf.Facilities[1].Direction = config.DirectionBoth
+	problems, err = ValidateConfig(conf, []byte(`[{pid: "20.500.12269/abc", sourceFolder: "/data/abc"}]`))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"facility Source: accessValue renders to an empty string",
+		"facility Source: sourcePath: template: :1:4: executing \"\" at <.Pidd>: can't evaluate field Pidd in type api.facilityPathContext",
+		"facility Destination: collection aaaa1111-22bb-cc44-dd5e-666667777777 is also used by facility Source",
+	}, problemStrings(problems))
+
+	_, err = ValidateConfig(conf, []byte(`[]`))
+	assert.ErrorContains(t, err, "no sample datasets defined")
+}
+
+func TestReadSampleContexts(t *testing.T) {
+	samples, err := readSampleContexts([]byte(`
+- pid: "20.500.12269/abc def"
+  sourceFolder: "/data/p1/abc"
+  username: jdoe
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, []facilityPathContext{{
+		DatasetFolder:        "abc",
+		SourceFolder:         "/data/p1/abc",
+		RelativeSourceFolder: "/data/p1/abc",
+		Pid:                  "20.500.12269/abc def",
+		PidShort:             "abc def",
+		PidPrefix:            "20.500.12269",
+		PidEncoded:           "20.500.12269%2Fabc%20def",
+		Username:             "jdoe",
+	}}, samples)
+}
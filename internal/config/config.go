@@ -1,25 +1,41 @@
 package config
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/logging"
 	util "github.com/SwissOpenEM/scicat-globus-proxy/internal/util"
-	"github.com/goccy/go-yaml"
 )
 
 type Config struct {
-	ScicatUrl  string           `yaml:"scicatUrl"`
-	Facilities []FacilityConfig `yaml:"facilities"`
-	Port       uint             `yaml:"port"`
-	Task       TaskConfig       `yaml:"task,omitempty"`
+	ScicatUrl        string           `yaml:"scicatUrl"`
+	Facilities       []FacilityConfig `yaml:"facilities"`
+	FacilityDefaults FacilityConfig   `yaml:"facilityDefaults,omitempty"`
+	Port             uint             `yaml:"port"`
+	TLS              TLSConfig        `yaml:"tls,omitempty"`
+	Scicat           ScicatConfig     `yaml:"scicat,omitempty"`
+	Outbound         OutboundConfig   `yaml:"outbound,omitempty"`
+	Auth             AuthConfig       `yaml:"auth,omitempty"`
+	Task             TaskConfig       `yaml:"task,omitempty"`
+	Reconcile        ReconcileConfig  `yaml:"reconcile,omitempty"`
+	Log              LogConfig        `yaml:"log,omitempty"`
+	Tracing          TracingConfig    `yaml:"tracing,omitempty"`
+	StateDir         string           `yaml:"stateDir,omitempty"`
+	AdminGroups      []string         `yaml:"adminGroups,omitempty"`
+	ShutdownTimeout  uint             `yaml:"shutdownTimeout,omitempty"`
+	Include          []string         `yaml:"include,omitempty"`
 }
 
 type TaskConfig struct {
 	MaxConcurrency int  `yaml:"maxConcurrency,omitempty"`
 	QueueSize      int  `yaml:"queueSize,omitempty"`
 	PollInterval   uint `yaml:"pollInterval,omitempty"`
+	// Seconds between progress updates of a job. A pointer, as 0 is a valid interval.
+	MinUpdateInterval *uint `yaml:"minUpdateInterval,omitempty"`
 }
 
 // Modify a TaskConfig by overridding any non-zero fields specified in the argument
@@ -37,15 +53,427 @@ func (conf *TaskConfig) Merge(overrides *TaskConfig) *TaskConfig {
 	if overrides.PollInterval != 0 {
 		conf.PollInterval = overrides.PollInterval
 	}
+	if overrides.MinUpdateInterval != nil {
+		conf.MinUpdateInterval = overrides.MinUpdateInterval
+	}
 	return conf
 }
 
 // Construct a FacilityConfig with default values
 func NewTaskConfig() TaskConfig {
+	minUpdateInterval := uint(30)
 	return TaskConfig{
-		MaxConcurrency: 10,
-		QueueSize:      0,
-		PollInterval:   10,
+		MaxConcurrency:    10,
+		QueueSize:         0,
+		PollInterval:      10,
+		MinUpdateInterval: &minUpdateInterval,
+	}
+}
+
+// Settings of the client of the SciCat backend
+type ScicatConfig struct {
+	// Timeout of a single request in seconds
+	Timeout uint `yaml:"timeout,omitempty"`
+	// Attempts of idempotent requests that fail with a network or server error
+	MaxAttempts uint `yaml:"maxAttempts,omitempty"`
+	// Consecutive failures after which requests fail fast
+	BreakerThreshold uint `yaml:"breakerThreshold,omitempty"`
+	// Seconds until a request is let through again after the breaker opened
+	BreakerCooldown uint `yaml:"breakerCooldown,omitempty"`
+}
+
+// Modify a ScicatConfig by overridding any non-zero fields specified in the argument
+func (conf *ScicatConfig) Merge(overrides *ScicatConfig) *ScicatConfig {
+	if conf == nil || overrides == nil {
+		return conf
+	}
+
+	if overrides.Timeout != 0 {
+		conf.Timeout = overrides.Timeout
+	}
+	if overrides.MaxAttempts != 0 {
+		conf.MaxAttempts = overrides.MaxAttempts
+	}
+	if overrides.BreakerThreshold != 0 {
+		conf.BreakerThreshold = overrides.BreakerThreshold
+	}
+	if overrides.BreakerCooldown != 0 {
+		conf.BreakerCooldown = overrides.BreakerCooldown
+	}
+	return conf
+}
+
+// Construct a ScicatConfig with default values
+func NewScicatConfig() ScicatConfig {
+	return ScicatConfig{
+		Timeout:          30,
+		MaxAttempts:      3,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30,
+	}
+}
+
+// Settings of the connections to SciCat and Globus
+type OutboundConfig struct {
+	TLS OutboundTLSConfig `yaml:"tls,omitempty"`
+	// Url of the HTTP proxy, defaults to the HTTPS_PROXY and HTTP_PROXY environment variables
+	Proxy string `yaml:"proxy,omitempty"`
+	// Comma separated hosts and domains that are reached without the proxy, like NO_PROXY
+	NoProxy string `yaml:"noProxy,omitempty"`
+}
+
+type OutboundTLSConfig struct {
+	// PEM file of CA certificates that are trusted in addition to the system CAs
+	CaFile string `yaml:"caFile,omitempty"`
+	// PEM files of the client certificate and its key, presented if the server asks for one
+	CertFile string `yaml:"certFile,omitempty"`
+	KeyFile  string `yaml:"keyFile,omitempty"`
+	// Minimum TLS version, 1.2 or 1.3
+	MinVersion string `yaml:"minVersion,omitempty"`
+}
+
+// Modify an OutboundConfig by overridding any non-zero fields specified in the argument
+func (conf *OutboundConfig) Merge(overrides *OutboundConfig) *OutboundConfig {
+	if conf == nil || overrides == nil {
+		return conf
+	}
+
+	if overrides.TLS.CaFile != "" {
+		conf.TLS.CaFile = overrides.TLS.CaFile
+	}
+	if overrides.TLS.CertFile != "" {
+		conf.TLS.CertFile = overrides.TLS.CertFile
+	}
+	if overrides.TLS.KeyFile != "" {
+		conf.TLS.KeyFile = overrides.TLS.KeyFile
+	}
+	if overrides.TLS.MinVersion != "" {
+		conf.TLS.MinVersion = overrides.TLS.MinVersion
+	}
+	if overrides.Proxy != "" {
+		conf.Proxy = overrides.Proxy
+	}
+	if overrides.NoProxy != "" {
+		conf.NoProxy = overrides.NoProxy
+	}
+	return conf
+}
+
+// Construct an OutboundConfig with default values
+func NewOutboundConfig() OutboundConfig {
+	return OutboundConfig{
+		TLS: OutboundTLSConfig{
+			MinVersion: TLSVersion12,
+		},
+	}
+}
+
+const (
+	TLSVersion12 = "1.2"
+	TLSVersion13 = "1.3"
+)
+
+var tlsVersions = map[string]uint16{
+	TLSVersion12: tls.VersionTLS12,
+	TLSVersion13: tls.VersionTLS13,
+}
+
+// The crypto/tls version of a configured minVersion, false if it's not supported
+func TLSVersion(version string) (uint16, bool) {
+	v, ok := tlsVersions[version]
+	return v, ok
+}
+
+type ClientAuth string
+
+const (
+	// Client certificates aren't requested
+	ClientAuthNone ClientAuth = "none"
+	// Client certificates are verified if the client sends one
+	ClientAuthOptional ClientAuth = "optional"
+	// Connections without a valid client certificate are rejected
+	ClientAuthRequire ClientAuth = "require"
+)
+
+// Settings of the TLS connections of API clients. The API is served over plain HTTP if no certificate is set.
+type TLSConfig struct {
+	// PEM files of the server certificate and its key, reloaded when they change
+	CertFile string `yaml:"certFile,omitempty"`
+	KeyFile  string `yaml:"keyFile,omitempty"`
+	// Minimum TLS version, 1.2 or 1.3
+	MinVersion string `yaml:"minVersion,omitempty"`
+	// PEM file of the CAs issuing client certificates, reloaded when it changes
+	ClientCaFile string `yaml:"clientCaFile,omitempty"`
+	// Verification of client certificates, defaults to optional if clientCaFile is set
+	ClientAuth ClientAuth `yaml:"clientAuth,omitempty"`
+}
+
+// Modify a TLSConfig by overridding any non-zero fields specified in the argument
+func (conf *TLSConfig) Merge(overrides *TLSConfig) *TLSConfig {
+	if conf == nil || overrides == nil {
+		return conf
+	}
+
+	if overrides.CertFile != "" {
+		conf.CertFile = overrides.CertFile
+	}
+	if overrides.KeyFile != "" {
+		conf.KeyFile = overrides.KeyFile
+	}
+	if overrides.MinVersion != "" {
+		conf.MinVersion = overrides.MinVersion
+	}
+	if overrides.ClientCaFile != "" {
+		conf.ClientCaFile = overrides.ClientCaFile
+	}
+	if overrides.ClientAuth != "" {
+		conf.ClientAuth = overrides.ClientAuth
+	}
+	return conf
+}
+
+// Construct a TLSConfig with default values
+func NewTLSConfig() TLSConfig {
+	return TLSConfig{
+		MinVersion: TLSVersion12,
+	}
+}
+
+// Whether the API is served over TLS
+func (conf TLSConfig) Enabled() bool {
+	return conf.CertFile != ""
+}
+
+type AuthMode string
+
+const (
+	// Tokens are resolved by the identity endpoint of SciCat
+	AuthModeScicat AuthMode = "scicat"
+	// Tokens are JWTs verified with the keys of a JWKS
+	AuthModeJwt AuthMode = "jwt"
+)
+
+// Settings of the authentication of API requests
+type AuthConfig struct {
+	Mode          AuthMode            `yaml:"mode,omitempty"`
+	Jwt           JwtConfig           `yaml:"jwt,omitempty"`
+	IdentityCache IdentityCacheConfig `yaml:"identityCache,omitempty"`
+}
+
+// Settings of the verification of JWTs
+type JwtConfig struct {
+	// Url or file of the JSON Web Key Set with the keys that sign the tokens
+	JwksUrl  string `yaml:"jwksUrl,omitempty"`
+	JwksFile string `yaml:"jwksFile,omitempty"`
+	// Seconds between two fetches of the keys from the url
+	RefreshInterval uint `yaml:"refreshInterval,omitempty"`
+	// Required aud claim
+	Audience string `yaml:"audience,omitempty"`
+	// Required iss claim, if set
+	Issuer string `yaml:"issuer,omitempty"`
+	// Claims of the user profile, nested claims are separated by dots
+	UsernameClaim string `yaml:"usernameClaim,omitempty"`
+	EmailClaim    string `yaml:"emailClaim,omitempty"`
+	NameClaim     string `yaml:"nameClaim,omitempty"`
+	GroupsClaim   string `yaml:"groupsClaim,omitempty"`
+}
+
+// Settings of the cache of the SciCat users resolved from tokens
+type IdentityCacheConfig struct {
+	Disabled bool `yaml:"disabled,omitempty"`
+	// Seconds a user is cached, at most until the token expires
+	Ttl uint `yaml:"ttl,omitempty"`
+	// Seconds a token rejected by SciCat is cached
+	NegativeTtl uint `yaml:"negativeTtl,omitempty"`
+	// Maximum number of cached tokens
+	MaxEntries uint `yaml:"maxEntries,omitempty"`
+}
+
+// Modify an AuthConfig by overridding any non-zero fields specified in the argument
+func (conf *AuthConfig) Merge(overrides *AuthConfig) *AuthConfig {
+	if conf == nil || overrides == nil {
+		return conf
+	}
+
+	if overrides.Mode != "" {
+		conf.Mode = overrides.Mode
+	}
+	if overrides.Jwt.JwksUrl != "" {
+		conf.Jwt.JwksUrl = overrides.Jwt.JwksUrl
+	}
+	if overrides.Jwt.JwksFile != "" {
+		conf.Jwt.JwksFile = overrides.Jwt.JwksFile
+	}
+	if overrides.Jwt.RefreshInterval != 0 {
+		conf.Jwt.RefreshInterval = overrides.Jwt.RefreshInterval
+	}
+	if overrides.Jwt.Audience != "" {
+		conf.Jwt.Audience = overrides.Jwt.Audience
+	}
+	if overrides.Jwt.Issuer != "" {
+		conf.Jwt.Issuer = overrides.Jwt.Issuer
+	}
+	if overrides.Jwt.UsernameClaim != "" {
+		conf.Jwt.UsernameClaim = overrides.Jwt.UsernameClaim
+	}
+	if overrides.Jwt.EmailClaim != "" {
+		conf.Jwt.EmailClaim = overrides.Jwt.EmailClaim
+	}
+	if overrides.Jwt.NameClaim != "" {
+		conf.Jwt.NameClaim = overrides.Jwt.NameClaim
+	}
+	if overrides.Jwt.GroupsClaim != "" {
+		conf.Jwt.GroupsClaim = overrides.Jwt.GroupsClaim
+	}
+	if overrides.IdentityCache.Disabled {
+		conf.IdentityCache.Disabled = overrides.IdentityCache.Disabled
+	}
+	if overrides.IdentityCache.Ttl != 0 {
+		conf.IdentityCache.Ttl = overrides.IdentityCache.Ttl
+	}
+	if overrides.IdentityCache.NegativeTtl != 0 {
+		conf.IdentityCache.NegativeTtl = overrides.IdentityCache.NegativeTtl
+	}
+	if overrides.IdentityCache.MaxEntries != 0 {
+		conf.IdentityCache.MaxEntries = overrides.IdentityCache.MaxEntries
+	}
+	return conf
+}
+
+// Construct an AuthConfig with default values
+func NewAuthConfig() AuthConfig {
+	return AuthConfig{
+		Mode: AuthModeScicat,
+		Jwt: JwtConfig{
+			RefreshInterval: 3600,
+			UsernameClaim:   "preferred_username",
+			EmailClaim:      "email",
+			NameClaim:       "name",
+			GroupsClaim:     "accessGroups",
+		},
+		IdentityCache: IdentityCacheConfig{
+			Ttl:         300,
+			NegativeTtl: 30,
+			MaxEntries:  10000,
+		},
+	}
+}
+
+type ReconcileAction string
+
+const (
+	// Only log and report the problem
+	ActionReport ReconcileAction = "report"
+	// Cancel orphaned globus tasks
+	ActionCancel ReconcileAction = "cancel"
+	// Mark orphaned SciCat jobs as failed
+	ActionFail ReconcileAction = "fail"
+)
+
+type ReconcileConfig struct {
+	Disabled      bool            `yaml:"disabled,omitempty"`
+	Interval      uint            `yaml:"interval,omitempty"`
+	GracePeriod   uint            `yaml:"gracePeriod,omitempty"`
+	OrphanedTasks ReconcileAction `yaml:"orphanedTasks,omitempty"`
+	OrphanedJobs  ReconcileAction `yaml:"orphanedJobs,omitempty"`
+}
+
+// Modify a ReconcileConfig by overridding any non-zero fields specified in the argument
+func (conf *ReconcileConfig) Merge(overrides *ReconcileConfig) *ReconcileConfig {
+	if conf == nil || overrides == nil {
+		return conf
+	}
+
+	if overrides.Disabled {
+		conf.Disabled = overrides.Disabled
+	}
+	if overrides.Interval != 0 {
+		conf.Interval = overrides.Interval
+	}
+	if overrides.GracePeriod != 0 {
+		conf.GracePeriod = overrides.GracePeriod
+	}
+	if overrides.OrphanedTasks != "" {
+		conf.OrphanedTasks = overrides.OrphanedTasks
+	}
+	if overrides.OrphanedJobs != "" {
+		conf.OrphanedJobs = overrides.OrphanedJobs
+	}
+	return conf
+}
+
+// Construct a ReconcileConfig with default values
+func NewReconcileConfig() ReconcileConfig {
+	return ReconcileConfig{
+		Interval:      600,
+		GracePeriod:   600,
+		OrphanedTasks: ActionReport,
+		OrphanedJobs:  ActionReport,
+	}
+}
+
+type LogConfig struct {
+	// text or json
+	Format string `yaml:"format,omitempty"`
+	// debug, info, warn or error
+	Level string `yaml:"level,omitempty"`
+}
+
+// Modify a LogConfig by overridding any non-zero fields specified in the argument
+func (conf *LogConfig) Merge(overrides *LogConfig) *LogConfig {
+	if conf == nil || overrides == nil {
+		return conf
+	}
+
+	if overrides.Format != "" {
+		conf.Format = overrides.Format
+	}
+	if overrides.Level != "" {
+		conf.Level = overrides.Level
+	}
+	return conf
+}
+
+// Construct a LogConfig with default values
+func NewLogConfig() LogConfig {
+	return LogConfig{
+		Format: logging.FormatText,
+		Level:  "info",
+	}
+}
+
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// OTLP/HTTP endpoint, defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// Fraction of the traces started by the proxy that are sampled. A pointer, as 0 is a valid ratio.
+	SampleRatio *float64 `yaml:"sampleRatio,omitempty"`
+}
+
+// Modify a TracingConfig by overridding any non-zero fields specified in the argument
+func (conf *TracingConfig) Merge(overrides *TracingConfig) *TracingConfig {
+	if conf == nil || overrides == nil {
+		return conf
+	}
+
+	if overrides.Enabled {
+		conf.Enabled = overrides.Enabled
+	}
+	if overrides.Endpoint != "" {
+		conf.Endpoint = overrides.Endpoint
+	}
+	if overrides.SampleRatio != nil {
+		conf.SampleRatio = overrides.SampleRatio
+	}
+	return conf
+}
+
+// Construct a TracingConfig with default values
+func NewTracingConfig() TracingConfig {
+	sampleRatio := 1.0
+	return TracingConfig{
+		Enabled:     false,
+		SampleRatio: &sampleRatio,
 	}
 }
 
@@ -122,43 +550,127 @@ const confFileName string = "scicat-globus-proxy-config.yaml"
 
 // Read the config file
 func ReadConfig() (Config, error) {
-	userConfigDir, err := os.UserConfigDir()
+	path, err := FindConfigFile("")
 	if err != nil {
 		return Config{}, err
 	}
+	return ReadConfigFile(path)
+}
+
+// Find the config file. An explicit path takes precedence, then the path in the
+// SCICAT_GLOBUS_PROXY_CONFIG environment variable, then the file next to the
+// executable, and finally the file in the user config directory.
+func FindConfigFile(explicitPath string) (string, error) {
+	if explicitPath != "" {
+		return explicitPath, nil
+	}
+	if envPath := os.Getenv(ConfigPathEnv); envPath != "" {
+		return envPath, nil
+	}
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
 	executablePath, err := os.Executable()
 	if err != nil {
-		return Config{}, err
+		return "", err
 	}
 
 	primaryConfPath := filepath.Join(filepath.Dir(executablePath), confFileName)
 	secondaryConfPath := filepath.Join(userConfigDir, "scicat-globus-proxy", confFileName)
 
-	f, err := os.ReadFile(primaryConfPath)
-	if err != nil {
-		f, err = os.ReadFile(secondaryConfPath)
+	for _, path := range []string{primaryConfPath, secondaryConfPath} {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
 	}
+	return "", fmt.Errorf("no config file found at \"%s\" or \"%s\"", primaryConfPath, secondaryConfPath)
+}
+
+// Read the config file, together with the files it includes and the files in conf.d next to it
+func ReadConfigFile(path string) (Config, error) {
+	sources, err := readConfigSources(path)
 	if err != nil {
-		return Config{}, fmt.Errorf("no config file found at \"%s\" or \"%s\"", primaryConfPath, secondaryConfPath)
+		return Config{}, err
 	}
-	return ReadConfigFromBytes(f)
+	return readConfig(sources)
 }
 
+// Read a single config file. Includes are only supported by ReadConfigFile.
 func ReadConfigFromBytes(contents []byte) (Config, error) {
-	var conf Config
-	if err := yaml.Unmarshal(contents, &conf); err != nil {
+	source, err := interpolateEnv(configSource{path: "config", contents: contents})
+	if err != nil {
+		return Config{}, err
+	}
+	conf, err := readConfig([]configSource{source})
+	if err == nil && len(conf.Include) > 0 {
+		return Config{}, fmt.Errorf("include is only supported when reading the config from a file")
+	}
+	return conf, err
+}
+
+func readConfig(sources []configSource) (Config, error) {
+	conf, err := mergeConfigSources(sources)
+	if err != nil {
+		return Config{}, err
+	}
+	if err := applyEnvOverrides(&conf); err != nil {
 		return Config{}, err
 	}
 
 	// Set defaults
+	scicat := NewScicatConfig()
+	scicat.Merge(&conf.Scicat)
+	conf.Scicat = scicat
+
+	tlsConf := NewTLSConfig()
+	tlsConf.Merge(&conf.TLS)
+	conf.TLS = tlsConf
+	if conf.TLS.ClientAuth == "" {
+		conf.TLS.ClientAuth = ClientAuthNone
+		if conf.TLS.ClientCaFile != "" {
+			conf.TLS.ClientAuth = ClientAuthOptional
+		}
+	}
+
+	outbound := NewOutboundConfig()
+	outbound.Merge(&conf.Outbound)
+	conf.Outbound = outbound
+
+	auth := NewAuthConfig()
+	auth.Merge(&conf.Auth)
+	conf.Auth = auth
+
 	task := NewTaskConfig()
 	task.Merge(&conf.Task)
 	conf.Task = task
 
+	reconcile := NewReconcileConfig()
+	reconcile.Merge(&conf.Reconcile)
+	conf.Reconcile = reconcile
+
+	logConf := NewLogConfig()
+	logConf.Merge(&conf.Log)
+	conf.Log = logConf
+
+	tracing := NewTracingConfig()
+	tracing.Merge(&conf.Tracing)
+	conf.Tracing = tracing
+
+	if conf.ShutdownTimeout == 0 {
+		conf.ShutdownTimeout = 30
+	}
+
+	// Facilities are layered on the built-in defaults and the facilityDefaults section
+	if conf.FacilityDefaults.Name != "" {
+		return Config{}, fmt.Errorf("facilityDefaults can't set a name")
+	}
+	defaults := NewFacilityConfig().Merge(&conf.FacilityDefaults)
+	conf.FacilityDefaults = *defaults
 	for i, facility := range conf.Facilities {
-		merged := NewFacilityConfig()
+		merged := *defaults
 		merged.Merge(&facility)
-		conf.Facilities[i] = *merged
+		conf.Facilities[i] = merged
 	}
 
 	// Validate required fields
@@ -173,10 +685,90 @@ func ReadConfigFromBytes(contents []byte) (Config, error) {
 			return Config{}, fmt.Errorf("missing Name for facility %v", i)
 		}
 	}
+	switch conf.Log.Format {
+	case logging.FormatText, logging.FormatJSON:
+	default:
+		return Config{}, fmt.Errorf("invalid log.format '%s', expected '%s' or '%s'", conf.Log.Format, logging.FormatText, logging.FormatJSON)
+	}
+	if _, err := logging.ParseLevel(conf.Log.Level); err != nil {
+		return Config{}, fmt.Errorf("log.level: %w", err)
+	}
+	if ratio := conf.Tracing.SampleRatio; ratio != nil && (*ratio < 0 || *ratio > 1) {
+		return Config{}, fmt.Errorf("invalid tracing.sampleRatio %v, expected a value between 0 and 1", *ratio)
+	}
+	if _, ok := TLSVersion(conf.TLS.MinVersion); !ok {
+		return Config{}, fmt.Errorf("invalid tls.minVersion '%s', expected '%s' or '%s'", conf.TLS.MinVersion, TLSVersion12, TLSVersion13)
+	}
+	if (conf.TLS.CertFile == "") != (conf.TLS.KeyFile == "") {
+		return Config{}, fmt.Errorf("tls.certFile and tls.keyFile must be set together")
+	}
+	switch conf.TLS.ClientAuth {
+	case ClientAuthNone:
+	case ClientAuthOptional, ClientAuthRequire:
+		if conf.TLS.ClientCaFile == "" {
+			return Config{}, fmt.Errorf("tls.clientAuth '%s' requires tls.clientCaFile", conf.TLS.ClientAuth)
+		}
+	default:
+		return Config{}, fmt.Errorf("invalid tls.clientAuth '%s', expected '%s', '%s' or '%s'", conf.TLS.ClientAuth, ClientAuthNone, ClientAuthOptional, ClientAuthRequire)
+	}
+	if conf.TLS.ClientCaFile != "" && !conf.TLS.Enabled() {
+		return Config{}, fmt.Errorf("tls.clientCaFile requires tls.certFile and tls.keyFile")
+	}
+	if _, ok := TLSVersion(conf.Outbound.TLS.MinVersion); !ok {
+		return Config{}, fmt.Errorf("invalid outbound.tls.minVersion '%s', expected '%s' or '%s'", conf.Outbound.TLS.MinVersion, TLSVersion12, TLSVersion13)
+	}
+	if (conf.Outbound.TLS.CertFile == "") != (conf.Outbound.TLS.KeyFile == "") {
+		return Config{}, fmt.Errorf("outbound.tls.certFile and outbound.tls.keyFile must be set together")
+	}
+	if conf.Outbound.Proxy != "" {
+		if proxyUrl, err := url.Parse(conf.Outbound.Proxy); err != nil || proxyUrl.Scheme == "" || proxyUrl.Host == "" {
+			return Config{}, fmt.Errorf("invalid outbound.proxy '%s', expected a url like http://proxy:3128", conf.Outbound.Proxy)
+		}
+	}
+	switch conf.Auth.Mode {
+	case AuthModeScicat:
+	case AuthModeJwt:
+		if (conf.Auth.Jwt.JwksUrl == "") == (conf.Auth.Jwt.JwksFile == "") {
+			return Config{}, fmt.Errorf("auth.mode '%s' requires one of auth.jwt.jwksUrl and auth.jwt.jwksFile", AuthModeJwt)
+		}
+		if conf.Auth.Jwt.Audience == "" {
+			return Config{}, fmt.Errorf("auth.mode '%s' requires auth.jwt.audience", AuthModeJwt)
+		}
+		if conf.Auth.Jwt.JwksUrl != "" {
+			if jwksUrl, err := url.Parse(conf.Auth.Jwt.JwksUrl); err != nil || jwksUrl.Scheme == "" || jwksUrl.Host == "" {
+				return Config{}, fmt.Errorf("invalid auth.jwt.jwksUrl '%s'", conf.Auth.Jwt.JwksUrl)
+			}
+		}
+	default:
+		return Config{}, fmt.Errorf("invalid auth.mode '%s', expected '%s' or '%s'", conf.Auth.Mode, AuthModeScicat, AuthModeJwt)
+	}
+	switch conf.Reconcile.OrphanedTasks {
+	case ActionReport, ActionCancel:
+	default:
+		return Config{}, fmt.Errorf("invalid reconcile.orphanedTasks '%s', expected '%s' or '%s'", conf.Reconcile.OrphanedTasks, ActionReport, ActionCancel)
+	}
+	switch conf.Reconcile.OrphanedJobs {
+	case ActionReport, ActionFail:
+	default:
+		return Config{}, fmt.Errorf("invalid reconcile.orphanedJobs '%s', expected '%s' or '%s'", conf.Reconcile.OrphanedJobs, ActionReport, ActionFail)
+	}
 
 	return conf, nil
 }
 
+// Get the directory where the proxy persists its state between restarts.
+// Defaults to a subdirectory of the user config directory.
+func (conf *Config) GetStateDir() (string, error) {
+	if conf.StateDir != "" {
+		return conf.StateDir, nil
+	}
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userConfigDir, "scicat-globus-proxy", "state"), nil
+}
+
 // Variables available to scopes for templating
 type globusContext struct {
 	Name       string
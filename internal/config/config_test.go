@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/goccy/go-yaml"
@@ -65,3 +66,193 @@ facilities:
 	assert.Equal(t, "aaaa1111-22bb-cc44-dd5e-666667777777", fac.Collection)
 	assert.Equal(t, `/archive/{{ replace .Pid "." "-" }}/{{ .SourceFolder }}`, fac.DestinationPath)
 }
+
+func TestFacilityDefaults(t *testing.T) {
+	content := `
+scicatUrl: "http://backend.localhost"
+port: 1234
+facilityDefaults:
+  accessPath: profile.username
+  sourcePath: "/data/{{ .RelativeSourceFolder }}"
+  destinationPath: "/archive/{{ .PidShort }}"
+facilities:
+  - name: "Default"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+  - name: "Override"
+    collection: bbbb2222-33cc-ff55-ee6e-777778888888
+    destinationPath: "/other/{{ .PidShort }}"
+`
+	conf, err := ReadConfigFromBytes([]byte(content))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(conf.Facilities))
+
+	fac := conf.Facilities[0]
+	assert.Equal(t, "profile.username", fac.AccessPath)
+	assert.Equal(t, "/data/{{ .RelativeSourceFolder }}", fac.SourcePath)
+	assert.Equal(t, "/archive/{{ .PidShort }}", fac.DestinationPath)
+	assert.Equal(t, "{{ .Name }}", fac.AccessValue) // built-in default
+	assert.Equal(t, 1, len(fac.Scopes))
+
+	fac = conf.Facilities[1]
+	assert.Equal(t, "profile.username", fac.AccessPath)
+	assert.Equal(t, "/other/{{ .PidShort }}", fac.DestinationPath)
+
+	_, err = ReadConfigFromBytes([]byte(strings.Replace(content, "facilityDefaults:\n", "facilityDefaults:\n  name: invalid\n", 1)))
+	assert.ErrorContains(t, err, "facilityDefaults")
+}
+
+func TestLogConfig(t *testing.T) {
+	content := `
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "TestFacility"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`
+	conf, err := ReadConfigFromBytes([]byte(content))
+	assert.Nil(t, err)
+	assert.Equal(t, "text", conf.Log.Format)
+	assert.Equal(t, "info", conf.Log.Level)
+
+	conf, err = ReadConfigFromBytes([]byte(content + "log:\n  format: json\n  level: debug\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, LogConfig{Format: "json", Level: "debug"}, conf.Log)
+
+	_, err = ReadConfigFromBytes([]byte(content + "log:\n  format: xml\n"))
+	assert.ErrorContains(t, err, "log.format")
+	_, err = ReadConfigFromBytes([]byte(content + "log:\n  level: verbose\n"))
+	assert.ErrorContains(t, err, "log.level")
+}
+
+func TestTracingConfig(t *testing.T) {
+	content := `
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "TestFacility"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`
+	conf, err := ReadConfigFromBytes([]byte(content))
+	assert.Nil(t, err)
+	assert.Equal(t, NewTracingConfig(), conf.Tracing)
+
+	conf, err = ReadConfigFromBytes([]byte(content + "tracing:\n  enabled: true\n  endpoint: http://collector:4318\n  sampleRatio: 0.25\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "http://collector:4318", conf.Tracing.Endpoint)
+	assert.Equal(t, 0.25, *conf.Tracing.SampleRatio)
+
+	// no traces are sampled with a ratio of 0
+	conf, err = ReadConfigFromBytes([]byte(content + "tracing:\n  enabled: true\n  sampleRatio: 0\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, *conf.Tracing.SampleRatio)
+
+	_, err = ReadConfigFromBytes([]byte(content + "tracing:\n  sampleRatio: 2\n"))
+	assert.ErrorContains(t, err, "tracing.sampleRatio")
+}
+
+func TestTaskConfig(t *testing.T) {
+	content := `
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "TestFacility"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`
+	conf, err := ReadConfigFromBytes([]byte(content))
+	assert.Nil(t, err)
+	assert.Equal(t, uint(30), *conf.Task.MinUpdateInterval)
+
+	// every changed progress is sent with an interval of 0
+	conf, err = ReadConfigFromBytes([]byte(content + "task:\n  minUpdateInterval: 0\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, uint(0), *conf.Task.MinUpdateInterval)
+	assert.Equal(t, uint(10), conf.Task.PollInterval)
+}
+
+func TestScicatConfig(t *testing.T) {
+	content := `
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "TestFacility"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`
+	conf, err := ReadConfigFromBytes([]byte(content))
+	assert.Nil(t, err)
+	assert.Equal(t, NewScicatConfig(), conf.Scicat)
+
+	conf, err = ReadConfigFromBytes([]byte(content + "scicat:\n  timeout: 5\n  maxAttempts: 1\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, ScicatConfig{Timeout: 5, MaxAttempts: 1, BreakerThreshold: 5, BreakerCooldown: 30}, conf.Scicat)
+}
+
+func TestOutboundConfig(t *testing.T) {
+	content := `
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "TestFacility"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`
+	conf, err := ReadConfigFromBytes([]byte(content))
+	assert.Nil(t, err)
+	assert.Equal(t, NewOutboundConfig(), conf.Outbound)
+
+	conf, err = ReadConfigFromBytes([]byte(content + "outbound:\n  tls:\n    caFile: /etc/ca.pem\n  proxy: http://proxy:3128\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, OutboundConfig{TLS: OutboundTLSConfig{CaFile: "/etc/ca.pem", MinVersion: "1.2"}, Proxy: "http://proxy:3128"}, conf.Outbound)
+
+	_, err = ReadConfigFromBytes([]byte(content + "outbound:\n  tls:\n    minVersion: \"1.1\"\n"))
+	assert.ErrorContains(t, err, "outbound.tls.minVersion")
+	_, err = ReadConfigFromBytes([]byte(content + "outbound:\n  tls:\n    certFile: /etc/cert.pem\n"))
+	assert.ErrorContains(t, err, "outbound.tls.keyFile")
+	_, err = ReadConfigFromBytes([]byte(content + "outbound:\n  proxy: proxy:3128\n"))
+	assert.ErrorContains(t, err, "outbound.proxy")
+}
+
+func TestTLSConfig(t *testing.T) {
+	content := `
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "TestFacility"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`
+	conf, err := ReadConfigFromBytes([]byte(content))
+	assert.Nil(t, err)
+	assert.False(t, conf.TLS.Enabled())
+	assert.Equal(t, ClientAuthNone, conf.TLS.ClientAuth)
+
+	conf, err = ReadConfigFromBytes([]byte(content + "tls:\n  certFile: cert.pem\n  keyFile: key.pem\n  clientCaFile: ca.pem\n"))
+	assert.Nil(t, err)
+	assert.True(t, conf.TLS.Enabled())
+	assert.Equal(t, ClientAuthOptional, conf.TLS.ClientAuth)
+
+	_, err = ReadConfigFromBytes([]byte(content + "tls:\n  certFile: cert.pem\n  keyFile: key.pem\n  clientAuth: require\n"))
+	assert.ErrorContains(t, err, "requires tls.clientCaFile")
+	_, err = ReadConfigFromBytes([]byte(content + "tls:\n  clientCaFile: ca.pem\n"))
+	assert.ErrorContains(t, err, "tls.clientCaFile requires")
+}
+
+func TestAuthConfig(t *testing.T) {
+	content := `
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "TestFacility"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`
+	conf, err := ReadConfigFromBytes([]byte(content))
+	assert.Nil(t, err)
+	assert.Equal(t, NewAuthConfig(), conf.Auth)
+
+	conf, err = ReadConfigFromBytes([]byte(content + "auth:\n  identityCache:\n    ttl: 60\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, IdentityCacheConfig{Ttl: 60, NegativeTtl: 30, MaxEntries: 10000}, conf.Auth.IdentityCache)
+
+	conf, err = ReadConfigFromBytes([]byte(content + "auth:\n  mode: jwt\n  jwt:\n    jwksUrl: https://sso.example.org/certs\n    audience: scicat\n    groupsClaim: realm_access.roles\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, AuthModeJwt, conf.Auth.Mode)
+	assert.Equal(t, "realm_access.roles", conf.Auth.Jwt.GroupsClaim)
+	assert.Equal(t, "preferred_username", conf.Auth.Jwt.UsernameClaim)
+
+	_, err = ReadConfigFromBytes([]byte(content + "auth:\n  mode: jwt\n  jwt:\n    audience: scicat\n"))
+	assert.ErrorContains(t, err, "auth.jwt.jwksUrl")
+	_, err = ReadConfigFromBytes([]byte(content + "auth:\n  mode: jwt\n  jwt:\n    jwksFile: jwks.json\n"))
+	assert.ErrorContains(t, err, "auth.jwt.audience")
+	_, err = ReadConfigFromBytes([]byte(content + "auth:\n  mode: oauth\n"))
+	assert.ErrorContains(t, err, "invalid auth.mode")
+}
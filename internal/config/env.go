@@ -0,0 +1,161 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/lexer"
+	"github.com/goccy/go-yaml/token"
+)
+
+const (
+	// Environment variable with the path of the config file
+	ConfigPathEnv = "SCICAT_GLOBUS_PROXY_CONFIG"
+	// Prefix of environment variables overriding top-level keys, eg. SCICAT_GLOBUS_PROXY_SCICAT_URL
+	envOverridePrefix = "SCICAT_GLOBUS_PROXY_"
+)
+
+// ${VAR} or ${VAR:-default}. $${ escapes a literal ${.
+var envReference = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// References are replaced by placeholders that are lexed as plain scalars, also in flow collections
+var envPlaceholder = regexp.MustCompile(`__scicat_globus_proxy_env_([0-9]+)__`)
+
+// Values that can be inserted into a plain scalar without changing the structure of the yaml
+var plainScalar = regexp.MustCompile(`^[A-Za-z0-9_./+~=-]([A-Za-z0-9_./:@%+~=-]*[A-Za-z0-9_./@%+~=-])?$`)
+
+// Replace references to environment variables in the scalars of the source. The values are
+// inserted as quoted scalars, unless they are plain words like numbers, so they can't change the
+// structure of the yaml. Comments are left untouched. Referencing an unset variable without a
+// default is an error.
+func interpolateEnv(source configSource) (configSource, error) {
+	refs := [][]byte{}
+	contents := envReference.ReplaceAllFunc(source.contents, func(ref []byte) []byte {
+		refs = append(refs, ref)
+		return fmt.Appendf(nil, "__scicat_globus_proxy_env_%d__", len(refs)-1)
+	})
+	if len(refs) == 0 {
+		return source, nil
+	}
+
+	// restore the references, or replace them by their values
+	replace := func(s string, line int, resolve bool) (string, error) {
+		var err error
+		replaced := envPlaceholder.ReplaceAllStringFunc(s, func(placeholder string) string {
+			i, _ := strconv.Atoi(envPlaceholder.FindStringSubmatch(placeholder)[1])
+			ref := refs[i]
+			if !resolve {
+				return string(ref)
+			}
+			if bytes.HasPrefix(ref, []byte("$$")) {
+				return string(ref[1:])
+			}
+			match := envReference.FindSubmatch(ref)
+			value, ok := os.LookupEnv(string(match[1]))
+			hasDefault := match[2] != nil
+			if ok && (value != "" || !hasDefault) {
+				return value
+			}
+			if hasDefault {
+				return string(match[3])
+			}
+			if err == nil {
+				err = fmt.Errorf("%s:%d: environment variable %s is not set", source.path, line, match[1])
+			}
+			return string(ref)
+		})
+		return replaced, err
+	}
+
+	var interpolated, lexed strings.Builder
+	var previous *token.Token
+	for _, tk := range lexer.Tokenize(string(contents)) {
+		lexed.WriteString(tk.Origin)
+		origin := tk.Origin
+		switch {
+		case !envPlaceholder.MatchString(origin):
+		case tk.Type == token.CommentType:
+			origin, _ = replace(origin, tk.Position.Line, false)
+		case previous != nil && (previous.Type == token.LiteralType || previous.Type == token.FoldedType):
+			// the indentation ends block scalars, so their values can't contain line breaks
+			value, err := replace(origin, tk.Position.Line, true)
+			if err != nil {
+				return configSource{}, err
+			}
+			if strings.Count(value, "\n") != strings.Count(origin, "\n") {
+				return configSource{}, fmt.Errorf("%s:%d: environment variables in block scalars can't contain line breaks", source.path, tk.Position.Line)
+			}
+			origin = value
+		case tk.Type == token.StringType || tk.Type == token.DoubleQuoteType || tk.Type == token.SingleQuoteType:
+			value, err := replace(tk.Value, tk.Position.Line, true)
+			if err != nil {
+				return configSource{}, err
+			}
+			if tk.Type != token.StringType || !plainScalar.MatchString(value) {
+				quoted, _ := json.Marshal(value)
+				value = string(quoted)
+			}
+			// keep the whitespace around the scalar
+			trimmed := strings.TrimLeft(origin, " \t\r\n")
+			origin = origin[:len(origin)-len(trimmed)] + value + trimmed[len(strings.TrimRight(trimmed, " \t\r\n")):]
+		default:
+			value, err := replace(origin, tk.Position.Line, true)
+			if err != nil {
+				return configSource{}, err
+			}
+			origin = value
+		}
+		interpolated.WriteString(origin)
+		previous = tk
+	}
+	// the lexer drops trailing whitespace
+	if !bytes.HasPrefix(contents, []byte(lexed.String())) {
+		return configSource{}, fmt.Errorf("%s: couldn't interpolate environment variables", source.path)
+	}
+	rest, _ := replace(string(contents[lexed.Len():]), 0, false)
+	interpolated.WriteString(rest)
+	return configSource{path: source.path, contents: []byte(interpolated.String())}, nil
+}
+
+// Override top-level keys from prefixed environment variables. The values are parsed as yaml.
+func applyEnvOverrides(conf *Config) error {
+	v := reflect.ValueOf(conf).Elem()
+	for i := range v.NumField() {
+		key := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		if key == "include" {
+			continue // includes are resolved before overrides
+		}
+		name := envOverrideName(key)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		field := reflect.New(v.Field(i).Type())
+		if err := yaml.Unmarshal([]byte(value), field.Interface()); err != nil {
+			return fmt.Errorf("invalid value of %s: %w", name, err)
+		}
+		v.Field(i).Set(field.Elem())
+	}
+	return nil
+}
+
+// Name of the environment variable overriding a top-level key, eg. scicatUrl -> SCICAT_GLOBUS_PROXY_SCICAT_URL
+func envOverrideName(key string) string {
+	var name strings.Builder
+	name.WriteString(envOverridePrefix)
+	for i, r := range key {
+		if unicode.IsUpper(r) && i > 0 {
+			name.WriteRune('_')
+		}
+		name.WriteRune(unicode.ToUpper(r))
+	}
+	return name.String()
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	t.Setenv("TEST_SCICAT_HOST", "backend.localhost")
+	t.Setenv("TEST_EMPTY", "")
+
+	source, err := interpolateEnv(configSource{path: "config", contents: []byte(`
+# ${TEST_UNSET_IN_COMMENT}
+scicatUrl: "http://${TEST_SCICAT_HOST}"
+port: ${TEST_PORT:-8080}
+stateDir: "${TEST_EMPTY:-/var/lib/proxy}"
+pattern: "$${LITERAL}"
+`)})
+	assert.Nil(t, err)
+	assert.Equal(t, `
+# ${TEST_UNSET_IN_COMMENT}
+scicatUrl: "http://backend.localhost"
+port: 8080
+stateDir: "/var/lib/proxy"
+pattern: "${LITERAL}"
+`, string(source.contents))
+
+	_, err = interpolateEnv(configSource{path: "config", contents: []byte("scicatUrl: x\nport: ${TEST_UNSET_PORT}\n")})
+	assert.ErrorContains(t, err, "config:2: environment variable TEST_UNSET_PORT is not set")
+}
+
+func TestInterpolateEnvValuesAreScalars(t *testing.T) {
+	t.Setenv("TEST_SCICAT_URL", "http://backend.localhost # comment\nport: 1")
+	t.Setenv("TEST_SECRET", "a\"b")
+	t.Setenv("TEST_PORT", "9090")
+
+	conf, err := ReadConfigFromBytes([]byte(`
+scicatUrl: ${TEST_SCICAT_URL}
+port: ${TEST_PORT}
+stateDir: "${TEST_SECRET}"
+facilities: [{name: ${TEST_SECRET}, collection: aaaa1111-22bb-cc44-dd5e-666667777777}]
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, "http://backend.localhost # comment\nport: 1", conf.ScicatUrl)
+	assert.Equal(t, uint(9090), conf.Port)
+	assert.Equal(t, "a\"b", conf.StateDir)
+	assert.Equal(t, "a\"b", conf.Facilities[0].Name)
+
+	_, err = interpolateEnv(configSource{path: "config", contents: []byte("description: |\n  ${TEST_SCICAT_URL}\n")})
+	assert.ErrorContains(t, err, "can't contain line breaks")
+}
+
+func TestEnvOverrides(t *testing.T) {
+	t.Setenv("SCICAT_GLOBUS_PROXY_SCICAT_URL", "http://override.localhost")
+	t.Setenv("SCICAT_GLOBUS_PROXY_PORT", "9090")
+	t.Setenv("SCICAT_GLOBUS_PROXY_TASK", "{pollInterval: 30}")
+
+	conf, err := ReadConfigFromBytes([]byte(`
+scicatUrl: "http://backend.localhost"
+port: 8080
+facilities:
+  - name: "TestFacility"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`))
+	assert.Nil(t, err)
+	assert.Equal(t, "http://override.localhost", conf.ScicatUrl)
+	assert.Equal(t, uint(9090), conf.Port)
+	assert.EqualValues(t, 30, conf.Task.PollInterval)
+
+	t.Setenv("SCICAT_GLOBUS_PROXY_PORT", "not a port")
+	_, err = ReadConfigFromBytes([]byte("scicatUrl: x\n"))
+	assert.ErrorContains(t, err, "invalid value of SCICAT_GLOBUS_PROXY_PORT")
+}
+
+func TestEnvOverrideName(t *testing.T) {
+	assert.Equal(t, "SCICAT_GLOBUS_PROXY_SCICAT_URL", envOverrideName("scicatUrl"))
+	assert.Equal(t, "SCICAT_GLOBUS_PROXY_FACILITY_DEFAULTS", envOverrideName("facilityDefaults"))
+	assert.Equal(t, "SCICAT_GLOBUS_PROXY_PORT", envOverrideName("port"))
+}
+
+func TestFindConfigFile(t *testing.T) {
+	t.Setenv(ConfigPathEnv, filepath.Join("env", confFileName))
+
+	path, err := FindConfigFile("explicit.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, "explicit.yaml", path)
+
+	path, err = FindConfigFile("")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join("env", confFileName), path)
+}
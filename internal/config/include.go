@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// Directory next to the main config file whose yaml files are merged into the config
+const confDirName = "conf.d"
+
+// A file contributing to the config
+type configSource struct {
+	path     string
+	contents []byte
+}
+
+// List the files making up the config: the main file, the files matching the
+// include globs in the given order, then the files in conf.d sorted by name.
+func configFiles(path string, contents []byte) ([]string, error) {
+	var header struct {
+		Include []string `yaml:"include"`
+	}
+	if err := yaml.Unmarshal(contents, &header); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	files := []string{path}
+	addFiles := func(matches []string) {
+		for _, match := range matches {
+			if !slices.Contains(files, match) {
+				files = append(files, match)
+			}
+		}
+	}
+	for _, pattern := range header.Include {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid include pattern \"%s\": %w", path, pattern, err)
+		}
+		addFiles(matches)
+	}
+	confDirFiles := []string{}
+	for _, ext := range []string{"*.yaml", "*.yml"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, confDirName, ext))
+		confDirFiles = append(confDirFiles, matches...)
+	}
+	slices.Sort(confDirFiles)
+	addFiles(confDirFiles)
+	return files, nil
+}
+
+// Read the main config file and all files it includes
+func readConfigSources(path string) ([]configSource, error) {
+	main, err := readConfigSource(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := configFiles(path, main.contents)
+	if err != nil {
+		return nil, err
+	}
+	sources := []configSource{main}
+	for _, file := range files[1:] {
+		source, err := readConfigSource(file)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// Read a file with environment variables interpolated
+func readConfigSource(path string) (configSource, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return configSource{}, err
+	}
+	return interpolateEnv(configSource{path: path, contents: contents})
+}
+
+// Merge the sources into one config. Included files can only add facilities and
+// override task settings. Facility names must be unique across all files.
+func mergeConfigSources(sources []configSource) (Config, error) {
+	var conf Config
+	if err := yaml.Unmarshal(sources[0].contents, &conf); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", sources[0].path, err)
+	}
+	locations, err := facilityLocations(sources[0], len(conf.Facilities))
+	if err != nil {
+		return Config{}, err
+	}
+
+	for _, source := range sources[1:] {
+		var fragment Config
+		if err := yaml.Unmarshal(source.contents, &fragment); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", source.path, err)
+		}
+		if key := unsupportedFragmentKey(fragment); key != "" {
+			return Config{}, fmt.Errorf("%s: '%s' can only be set in the main config file", source.path, key)
+		}
+		fragmentLocations, err := facilityLocations(source, len(fragment.Facilities))
+		if err != nil {
+			return Config{}, err
+		}
+		conf.Facilities = append(conf.Facilities, fragment.Facilities...)
+		locations = append(locations, fragmentLocations...)
+		conf.Task.Merge(&fragment.Task)
+	}
+
+	firstDefinition := map[string]int{}
+	for i, facility := range conf.Facilities {
+		if first, exists := firstDefinition[facility.Name]; exists && facility.Name != "" {
+			return Config{}, fmt.Errorf("%s: duplicate facility \"%s\", first defined at %s", locations[i], facility.Name, locations[first])
+		}
+		firstDefinition[facility.Name] = i
+	}
+	return conf, nil
+}
+
+// The yaml key of the first field other than facilities and task set in an included file
+func unsupportedFragmentKey(fragment Config) string {
+	v := reflect.ValueOf(fragment)
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if field.Name == "Facilities" || field.Name == "Task" || v.Field(i).IsZero() {
+			continue
+		}
+		return strings.Split(field.Tag.Get("yaml"), ",")[0]
+	}
+	return ""
+}
+
+// Position ("file:line") of the name of each facility in the source
+func facilityLocations(source configSource, count int) ([]string, error) {
+	file, err := parser.ParseBytes(source.contents, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source.path, err)
+	}
+	locations := make([]string, count)
+	for i := range count {
+		locations[i] = source.path
+		for _, key := range []string{"name", ""} {
+			path, err := yaml.PathString(strings.TrimSuffix(fmt.Sprintf("$.facilities[%d].%s", i, key), "."))
+			if err != nil {
+				return nil, err
+			}
+			if node, err := path.FilterFile(file); err == nil && node != nil {
+				locations[i] = fmt.Sprintf("%s:%d", source.path, node.GetToken().Position.Line)
+				break
+			}
+		}
+	}
+	return locations, nil
+}
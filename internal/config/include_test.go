@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestIncludes(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		confFileName: `
+scicatUrl: "http://backend.localhost"
+include:
+  - teams/*.yaml
+facilities:
+  - name: "Main"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+task:
+  pollInterval: 20
+  maxConcurrency: 5
+`,
+		"teams/b.yaml": `
+facilities:
+  - name: "TeamB"
+    collection: bbbb2222-33cc-ff55-ee6e-777778888888
+`,
+		"teams/a.yaml": `
+facilities:
+  - name: "TeamA"
+    collection: cccc2222-33cc-ff55-ee6e-777778888888
+task:
+  pollInterval: 30
+`,
+		"conf.d/10-extra.yaml": `
+facilities:
+  - name: "Extra"
+    collection: dddd2222-33cc-ff55-ee6e-777778888888
+task:
+  pollInterval: 40
+`,
+	})
+
+	conf, err := ReadConfigFile(filepath.Join(dir, confFileName))
+	assert.Nil(t, err)
+
+	names := []string{}
+	for _, facility := range conf.Facilities {
+		names = append(names, facility.Name)
+	}
+	assert.Equal(t, []string{"Main", "TeamA", "TeamB", "Extra"}, names)
+	assert.EqualValues(t, 40, conf.Task.PollInterval)
+	assert.Equal(t, 5, conf.Task.MaxConcurrency)
+	// defaults apply to included facilities
+	assert.Equal(t, DirectionBoth, conf.Facilities[3].Direction)
+}
+
+func TestDuplicateFacilities(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		confFileName: `
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "Main"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`,
+		"conf.d/team.yaml": `
+facilities:
+  - name: "Other"
+    collection: bbbb2222-33cc-ff55-ee6e-777778888888
+  - collection: cccc2222-33cc-ff55-ee6e-777778888888
+    name: "Main"
+`,
+	})
+
+	_, err := ReadConfigFile(filepath.Join(dir, confFileName))
+	assert.ErrorContains(t, err, filepath.Join(dir, "conf.d", "team.yaml")+":6: duplicate facility \"Main\"")
+	assert.ErrorContains(t, err, filepath.Join(dir, confFileName)+":4")
+}
+
+func TestIncludedFilesOnlySetFacilitiesAndTask(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		confFileName: `
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "Main"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`,
+		"conf.d/team.yaml": `
+scicatUrl: "http://other.localhost"
+`,
+	})
+
+	_, err := ReadConfigFile(filepath.Join(dir, confFileName))
+	assert.ErrorContains(t, err, "'scicatUrl' can only be set in the main config file")
+}
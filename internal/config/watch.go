@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Editors and config map updates touch the file several times in a row
+const reloadDebounce = 500 * time.Millisecond
+
+// Watch the config file, the files it includes and conf.d, and reload the config
+// when they change or on SIGHUP, until the context is cancelled. apply is only
+// called with valid configs that differ from the last applied one, and returns
+// whether it applied the config. Invalid configs are logged and ignored.
+func Watch(ctx context.Context, path string, apply func(Config) bool) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// Watch the directories, as files may be replaced instead of written to
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	last := []byte{}
+	if sources, err := readConfigSources(path); err == nil {
+		last = joinSources(sources)
+		watchSources(watcher, path, sources)
+	}
+
+	go func() {
+		defer watcher.Close()
+		defer signal.Stop(hangup)
+
+		debounce := time.NewTimer(reloadDebounce)
+		debounce.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				slog.Info("Received SIGHUP, reloading config", "path", path)
+				last = reload(watcher, path, last, true, apply)
+			case event := <-watcher.Events:
+				if event.Has(fsnotify.Chmod) {
+					continue
+				}
+				debounce.Reset(reloadDebounce)
+			case err := <-watcher.Errors:
+				slog.Warn("error watching config file", "path", path, "error", err)
+			case <-debounce.C:
+				last = reload(watcher, path, last, false, apply)
+			}
+		}
+	}()
+	return nil
+}
+
+// Read and apply the config if it differs from the last one, or if forced. Returns
+// the contents that are applied after the call.
+func reload(watcher *fsnotify.Watcher, path string, last []byte, force bool, apply func(Config) bool) []byte {
+	sources, err := readConfigSources(path)
+	if err != nil {
+		slog.Error("couldn't reload config, keeping the current config", "path", path, "error", err)
+		return last
+	}
+	if watcher != nil {
+		watchSources(watcher, path, sources)
+	}
+	contents := joinSources(sources)
+	if !force && bytes.Equal(contents, last) {
+		return last
+	}
+	conf, err := readConfig(sources)
+	if err != nil {
+		slog.Error("invalid config, keeping the current config", "path", path, "error", err)
+		return last
+	}
+	slog.Info("Reloading config", "path", path, "files", len(sources))
+	if !apply(conf) {
+		// retried on the next change, even if the contents are the same as now
+		return last
+	}
+	return contents
+}
+
+// Watch conf.d and the directories of included files, which may change with the config
+func watchSources(watcher *fsnotify.Watcher, path string, sources []configSource) {
+	dirs := []string{filepath.Join(filepath.Dir(path), confDirName)}
+	for _, source := range sources {
+		dirs = append(dirs, filepath.Dir(source.path))
+	}
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			slog.Warn("couldn't watch config directory", "dir", dir, "error", err)
+		}
+	}
+}
+
+// Concatenate the sources to detect changes of any of them
+func joinSources(sources []configSource) []byte {
+	joined := []byte{}
+	for _, source := range sources {
+		joined = append(joined, []byte(source.path)...)
+		joined = append(joined, 0)
+		joined = append(joined, source.contents...)
+		joined = append(joined, 0)
+	}
+	return joined
+}
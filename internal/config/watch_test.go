@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, confFileName)
+	valid := []byte(`
+scicatUrl: "http://backend.localhost"
+facilities:
+  - name: "TestFacility"
+    collection: aaaa1111-22bb-cc44-dd5e-666667777777
+`)
+	assert.Nil(t, os.WriteFile(path, valid, 0o600))
+
+	applied := []Config{}
+	accept := true
+	apply := func(conf Config) bool {
+		if accept {
+			applied = append(applied, conf)
+		}
+		return accept
+	}
+
+	// unchanged content is only applied when forced
+	last := reload(nil, path, nil, true, apply)
+	assert.Equal(t, 1, len(applied))
+	last = reload(nil, path, last, false, apply)
+	assert.Equal(t, 1, len(applied))
+
+	// invalid content keeps the last config
+	assert.Nil(t, os.WriteFile(path, []byte("facilities: []"), 0o600))
+	unchanged := reload(nil, path, last, false, apply)
+	assert.Equal(t, 1, len(applied))
+	assert.Equal(t, last, unchanged)
+
+	// changes in conf.d are picked up
+	assert.Nil(t, os.WriteFile(path, valid, 0o600))
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, confDirName), 0o700))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, confDirName, "task.yaml"), []byte("task:\n  pollInterval: 60\n"), 0o600))
+	// rejected configs aren't considered applied
+	accept = false
+	rejected := reload(nil, path, last, false, apply)
+	assert.Equal(t, last, rejected)
+	accept = true
+	reload(nil, path, rejected, false, apply)
+	assert.Equal(t, 2, len(applied))
+	assert.EqualValues(t, 60, applied[1].Task.PollInterval)
+}
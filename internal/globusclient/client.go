@@ -0,0 +1,163 @@
+// Globus service account client, with the calls missing from the globus module
+package globusclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/SwissOpenEM/globus"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	authBaseUrl     = "https://auth.globus.org/v2"
+	transferBaseUrl = "https://transfer.api.globusonline.org/v0.10"
+)
+
+// A globus client authenticated as a service account (2-legged OAUTH)
+type Client struct {
+	globus.GlobusClient
+	httpClient  *http.Client
+	credentials clientcredentials.Config
+	// client of the token requests
+	baseClient *http.Client
+}
+
+// Construct a client for a service account, connecting through transport. Fails if no
+// token can be obtained for the scopes.
+func NewServiceClient(ctx context.Context, transport http.RoundTripper, clientId string, clientSecret string, scopes []string) (Client, error) {
+	baseClient := &http.Client{Transport: transport}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, baseClient)
+	credentials := clientcredentials.Config{
+		ClientID:     clientId,
+		ClientSecret: clientSecret,
+		TokenURL:     authBaseUrl + "/oauth2/token",
+		Scopes:       scopes,
+	}
+	if _, err := credentials.Token(ctx); err != nil {
+		return Client{}, fmt.Errorf("error getting token for client: %w", err)
+	}
+	httpClient := credentials.Client(ctx)
+	return Client{
+		GlobusClient: globus.HttpClientToGlobusClient(httpClient),
+		httpClient:   httpClient,
+		credentials:  credentials,
+		baseClient:   baseClient,
+	}, nil
+}
+
+// Make the token requests in ctx use the transport of the client
+func (c Client) tokenContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, c.baseClient)
+}
+
+// Scopes of the tokens granted to the service account, including the tokens of other resource servers
+func (c Client) GrantedScopes(ctx context.Context) ([]string, error) {
+	token, err := c.credentials.Token(c.tokenContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return tokenScopes(token), nil
+}
+
+func tokenScopes(token *oauth2.Token) []string {
+	scopes := []string{}
+	if scope, ok := token.Extra("scope").(string); ok {
+		scopes = append(scopes, strings.Fields(scope)...)
+	}
+	if others, ok := token.Extra("other_tokens").([]any); ok {
+		for _, other := range others {
+			if otherToken, ok := other.(map[string]any); ok {
+				if scope, ok := otherToken["scope"].(string); ok {
+					scopes = append(scopes, strings.Fields(scope)...)
+				}
+			}
+		}
+	}
+	return scopes
+}
+
+// Request a token for a single scope, eg. to check that the service account may
+// use the data_access scope of a collection. Returns the scopes granted with the token.
+func (c Client) CheckScope(ctx context.Context, scope string) ([]string, error) {
+	credentials := c.credentials
+	credentials.Scopes = []string{scope}
+	token, err := credentials.Token(c.tokenContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return tokenScopes(token), nil
+}
+
+// Scopes that aren't granted. Dependent scopes, eg. the data_access scope of a collection
+// in brackets, aren't listed by the tokens, only the scope they depend on is compared.
+func MissingScopes(scopes []string, granted []string) []string {
+	missing := []string{}
+	for _, scope := range scopes {
+		base, _, _ := strings.Cut(scope, "[")
+		if !slices.Contains(granted, base) {
+			missing = append(missing, base)
+		}
+	}
+	return missing
+}
+
+// An error returned by the transfer API
+type TransferError struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e TransferError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// An entry of a directory listing
+type DirEntry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+}
+
+// List a directory on a collection
+func (c Client) ListDirectory(ctx context.Context, collection string, path string) ([]DirEntry, error) {
+	lsUrl := fmt.Sprintf("%s/operation/endpoint/%s/ls?path=%s", transferBaseUrl, url.PathEscape(collection), url.QueryEscape(path))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lsUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		transferErr := TransferError{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(body, &transferErr)
+		return nil, transferErr
+	}
+
+	var listing struct {
+		Data []DirEntry `json:"DATA"`
+	}
+	if err := json.Unmarshal(body, &listing); err != nil {
+		return nil, err
+	}
+	return listing.Data, nil
+}
@@ -0,0 +1,31 @@
+package globusclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenScopes(t *testing.T) {
+	token := (&oauth2.Token{AccessToken: "token"}).WithExtra(map[string]any{
+		"scope": "urn:globus:auth:scope:transfer.api.globus.org:all",
+		"other_tokens": []any{
+			map[string]any{"scope": "openid email"},
+			"invalid",
+		},
+	})
+	assert.Equal(t, []string{"urn:globus:auth:scope:transfer.api.globus.org:all", "openid", "email"}, tokenScopes(token))
+	assert.Equal(t, []string{}, tokenScopes(&oauth2.Token{}))
+}
+
+func TestMissingScopes(t *testing.T) {
+	granted := []string{"urn:globus:auth:scope:transfer.api.globus.org:all", "openid"}
+	assert.Equal(t, []string{}, MissingScopes([]string{"urn:globus:auth:scope:transfer.api.globus.org:all[*https://auth.globus.org/scopes/1234/data_access]"}, granted))
+	assert.Equal(t, []string{"email"}, MissingScopes([]string{"openid", "email"}, granted))
+}
+
+func TestTransferError(t *testing.T) {
+	assert.Equal(t, "ConsentRequired: Missing required data_access consent", TransferError{StatusCode: 403, Code: "ConsentRequired", Message: "Missing required data_access consent"}.Error())
+	assert.Equal(t, "status 502", TransferError{StatusCode: 502}.Error())
+}
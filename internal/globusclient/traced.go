@@ -0,0 +1,57 @@
+package globusclient
+
+import (
+	"context"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Traced calls of the globus client. The globus module doesn't take a context,
+// so the spans only measure the calls and record their errors.
+
+func GetTask(ctx context.Context, client globus.GlobusClient, taskId string) (globus.Task, error) {
+	_, span := tracing.Start(ctx, "globus.GetTask", attribute.String("globus.task_id", taskId))
+	task, err := client.TransferGetTaskByID(taskId)
+	span.SetAttributes(attribute.String("globus.task_status", task.Status))
+	tracing.End(span, err)
+	return task, err
+}
+
+func CancelTask(ctx context.Context, client globus.GlobusClient, taskId string) (globus.Result, error) {
+	_, span := tracing.Start(ctx, "globus.CancelTask", attribute.String("globus.task_id", taskId))
+	result, err := client.TransferCancelTaskByID(taskId)
+	tracing.End(span, err)
+	return result, err
+}
+
+func ListTasks(ctx context.Context, client globus.GlobusClient, offset uint, limit uint) (globus.TaskList, error) {
+	_, span := tracing.Start(ctx, "globus.ListTasks", attribute.Int("globus.offset", int(offset)))
+	tasks, err := client.TransferGetTaskList(offset, limit)
+	tracing.End(span, err)
+	return tasks, err
+}
+
+func TransferFolderSync(ctx context.Context, client globus.GlobusClient, sourceEndpoint string, sourcePath string, destEndpoint string, destPath string) (globus.TransferResult, error) {
+	_, span := tracing.Start(ctx, "globus.TransferFolderSync",
+		attribute.String("globus.source_endpoint", sourceEndpoint),
+		attribute.String("globus.destination_endpoint", destEndpoint),
+	)
+	result, err := client.TransferFolderSync(sourceEndpoint, sourcePath, destEndpoint, destPath, true)
+	span.SetAttributes(attribute.String("globus.task_id", result.TaskId))
+	tracing.End(span, err)
+	return result, err
+}
+
+func TransferFileList(ctx context.Context, client globus.GlobusClient, sourceEndpoint string, sourcePath string, destEndpoint string, destPath string, paths []string, isSymlinks []bool) (globus.TransferResult, error) {
+	_, span := tracing.Start(ctx, "globus.TransferFileList",
+		attribute.String("globus.source_endpoint", sourceEndpoint),
+		attribute.String("globus.destination_endpoint", destEndpoint),
+		attribute.Int("globus.file_count", len(paths)),
+	)
+	result, err := client.TransferFileList(sourceEndpoint, sourcePath, destEndpoint, destPath, paths, isSymlinks, true)
+	span.SetAttributes(attribute.String("globus.task_id", result.TaskId))
+	tracing.End(span, err)
+	return result, err
+}
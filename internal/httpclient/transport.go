@@ -0,0 +1,73 @@
+// Transport of the connections to SciCat and Globus
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Construct a transport with the TLS and proxy settings of conf. Fails if the certificate files can't be loaded.
+func NewTransport(conf config.OutboundConfig) (*http.Transport, error) {
+	tlsConfig, err := newTLSConfig(conf.TLS)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if conf.Proxy != "" {
+		transport.Proxy = proxyFunc(conf.Proxy, conf.NoProxy)
+	}
+	return transport, nil
+}
+
+func newTLSConfig(conf config.OutboundTLSConfig) (*tls.Config, error) {
+	minVersion, ok := config.TLSVersion(conf.MinVersion)
+	if !ok && conf.MinVersion != "" {
+		return nil, fmt.Errorf("unsupported TLS version '%s'", conf.MinVersion)
+	}
+	tlsConfig := &tls.Config{MinVersion: max(minVersion, tls.VersionTLS12)}
+
+	if conf.CaFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(conf.CaFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file '%s'", conf.CaFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if conf.CertFile != "" || conf.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// Proxy selection of a transport, where noProxy lists the hosts that are reached directly
+func proxyFunc(proxy string, noProxy string) func(*http.Request) (*url.URL, error) {
+	proxyConfig := httpproxy.Config{
+		HTTPProxy:  proxy,
+		HTTPSProxy: proxy,
+		NoProxy:    noProxy,
+	}
+	selectProxy := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return selectProxy(req.URL)
+	}
+}
@@ -0,0 +1,61 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// the test server's certificate isn't trusted by default
+	transport, err := NewTransport(config.NewOutboundConfig())
+	assert.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	assert.Error(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, os.WriteFile(caFile, caPem, 0o600))
+	conf := config.NewOutboundConfig()
+	conf.TLS.CaFile = caFile
+	transport, err = NewTransport(conf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), transport.TLSClientConfig.MinVersion)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	conf.TLS.CaFile = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = NewTransport(conf)
+	assert.ErrorContains(t, err, "couldn't read CA file")
+}
+
+func TestProxy(t *testing.T) {
+	conf := config.NewOutboundConfig()
+	conf.Proxy = "http://proxy.example.org:3128"
+	conf.NoProxy = "scicat.internal"
+	transport, err := NewTransport(conf)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "https://transfer.api.globusonline.org/v0.10", nil)
+	proxyUrl, err := transport.Proxy(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "proxy.example.org:3128", proxyUrl.Host)
+
+	req = httptest.NewRequest(http.MethodGet, "https://backend.scicat.internal/api/v3", nil)
+	proxyUrl, err = transport.Proxy(req)
+	assert.NoError(t, err)
+	assert.Nil(t, proxyUrl)
+}
@@ -0,0 +1,262 @@
+// Load the keys of a JSON Web Key Set (RFC 7517) to verify JWTs
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Timeout of fetching the keys from the url
+const fetchTimeout = 10 * time.Second
+
+// Minimum time between two fetches caused by tokens signed with an unknown key
+const minRefetchInterval = time.Minute
+
+// A key of the set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// Keys of a key set loaded from a url or a file. Keys from a url are fetched again after the
+// refresh interval, and when a token is signed by an unknown key, eg. after a key rotation.
+type KeySet struct {
+	url             string
+	httpClient      *http.Client
+	refreshInterval time.Duration
+
+	// guards the fields below. The keys are replaced, never modified, so a map read under the
+	// mutex can be used after releasing it.
+	mutex   sync.Mutex
+	keys    map[string]any
+	fetched time.Time
+	// closed when the running fetch completes, nil if none is running
+	fetching chan struct{}
+	now      func() time.Time
+}
+
+// Construct a key set fetched from url with the client. The keys are fetched on first use.
+func NewRemoteKeySet(url string, httpClient *http.Client, refreshInterval time.Duration) *KeySet {
+	return &KeySet{
+		url:             url,
+		httpClient:      httpClient,
+		refreshInterval: refreshInterval,
+		keys:            map[string]any{},
+		now:             time.Now,
+	}
+}
+
+// Load a key set from a file
+func NewFileKeySet(path string) (*KeySet, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read JWKS file: %w", err)
+	}
+	keys, err := parseKeySet(contents)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS file '%s': %w", path, err)
+	}
+	return &KeySet{keys: keys, now: time.Now}, nil
+}
+
+// Get the key that signed a token, identified by the kid header. Tokens without a kid
+// are accepted if the set has a single key. Usable as jwt.Keyfunc.
+func (k *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if k.url != "" {
+		k.refresh(kid)
+	}
+
+	k.mutex.Lock()
+	keys := k.keys
+	k.mutex.Unlock()
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key '%s'", kid)
+}
+
+// Fetch the keys if they expired or kid is unknown. A single fetch runs at a time, without holding
+// the mutex, so tokens signed with known keys are verified meanwhile. Tokens signed with an unknown
+// key wait for the running fetch.
+func (k *KeySet) refresh(kid string) {
+	k.mutex.Lock()
+	_, known := k.keys[kid]
+	if fetching := k.fetching; fetching != nil {
+		k.mutex.Unlock()
+		if !known {
+			<-fetching
+		}
+		return
+	}
+	now := k.now()
+	sinceFetch := now.Sub(k.fetched)
+	if sinceFetch < k.refreshInterval && (known || sinceFetch < minRefetchInterval) {
+		k.mutex.Unlock()
+		return
+	}
+	fetching := make(chan struct{})
+	k.fetching = fetching
+	k.fetched = now
+	k.mutex.Unlock()
+
+	keys, err := k.fetch()
+
+	k.mutex.Lock()
+	if err == nil {
+		k.keys = keys
+	}
+	k.fetching = nil
+	k.mutex.Unlock()
+	close(fetching)
+}
+
+// Fetch the keys from the url. If it fails, the keys fetched before are kept.
+func (k *KeySet) fetch() (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	keys, err := k.fetchKeys(ctx)
+	if err != nil {
+		slog.Warn("couldn't fetch JWKS, keeping the current keys", "url", k.url, "error", err)
+		return nil, err
+	}
+	slog.Debug("Fetched JWKS", "url", k.url, "keys", len(keys))
+	return keys, nil
+}
+
+func (k *KeySet) fetchKeys(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeySet(body)
+}
+
+// Parse the signature keys of a key set by kid. Keys of unsupported types are skipped.
+func parseKeySet(contents []byte) (map[string]any, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(contents, &set); err != nil {
+		return nil, err
+	}
+	keys := map[string]any{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			slog.Warn("skipping JWKS key", "kid", jwk.Kid, "kty", jwk.Kty, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable signature keys")
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) publicKey() (any, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeInt(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeInt(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("e: %w", err)
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("e is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		// uncompressed point encoding
+		point := append(append([]byte{4}, x...), y...)
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve '%s'", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", jwk.Kty)
+	}
+}
+
+// Decode a base64url encoded big-endian integer
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
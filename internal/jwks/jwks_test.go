@@ -0,0 +1,157 @@
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestParseKeySet(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	ecPoint, _ := ecKey.PublicKey.Bytes()
+	contents := `{"keys": [
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": "` + encode(rsaKey.N.Bytes()) + `", "e": "` + encode(big.NewInt(int64(rsaKey.E)).Bytes()) + `"},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": "` + encode(ecPoint[1:33]) + `", "y": "` + encode(ecPoint[33:]) + `"},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "` + encode(edKey) + `"},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "` + encode(rsaKey.N.Bytes()) + `", "e": "AQAB"},
+		{"kty": "oct", "kid": "secret", "k": "c2VjcmV0"}
+	]}`
+
+	keys, err := parseKeySet([]byte(contents))
+	assert.NoError(t, err)
+	assert.Len(t, keys, 3)
+	assert.True(t, rsaKey.PublicKey.Equal(keys["rsa"]))
+	assert.True(t, ecKey.PublicKey.Equal(keys["ec"]))
+	assert.True(t, edKey.Equal(keys["ed"]))
+
+	_, err = parseKeySet([]byte(`{"keys": []}`))
+	assert.Error(t, err)
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	point, _ := key.PublicKey.Bytes()
+	kid := atomic.Value{}
+	kid.Store("old")
+	fetches := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte(`{"keys": [{"kty": "EC", "kid": "` + kid.Load().(string) + `", "crv": "P-256", "x": "` + encode(point[1:33]) + `", "y": "` + encode(point[33:]) + `"}]}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	keySet := NewRemoteKeySet(server.URL, server.Client(), time.Hour)
+	keySet.now = func() time.Time { return now }
+	token := func(kid string) *jwt.Token {
+		return &jwt.Token{Header: map[string]any{"kid": kid}}
+	}
+
+	_, err := keySet.Keyfunc(token("old"))
+	assert.NoError(t, err)
+	_, err = keySet.Keyfunc(token("old"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// unknown keys are fetched again, at most once a minute
+	kid.Store("new")
+	_, err = keySet.Keyfunc(token("new"))
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+	now = now.Add(time.Minute)
+	_, err = keySet.Keyfunc(token("new"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	// the keys are kept if fetching fails
+	server.Close()
+	now = now.Add(time.Hour)
+	_, err = keySet.Keyfunc(token("new"))
+	assert.NoError(t, err)
+}
+
+// A slow fetch doesn't block tokens signed with known keys, and runs only once
+func TestRemoteKeySetSingleFetch(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	point, _ := key.PublicKey.Bytes()
+	kid := atomic.Value{}
+	kid.Store("old")
+	fetches := atomic.Int32{}
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			started <- struct{}{}
+			<-release
+		}
+		_, _ = w.Write([]byte(`{"keys": [{"kty": "EC", "kid": "` + kid.Load().(string) + `", "crv": "P-256", "x": "` + encode(point[1:33]) + `", "y": "` + encode(point[33:]) + `"}]}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	keySet := NewRemoteKeySet(server.URL, server.Client(), time.Hour)
+	keySet.now = func() time.Time { return now }
+	token := func(kid string) *jwt.Token {
+		return &jwt.Token{Header: map[string]any{"kid": kid}}
+	}
+	_, err := keySet.Keyfunc(token("old"))
+	assert.NoError(t, err)
+
+	// the keys expired, the refresh blocks in the server
+	kid.Store("new")
+	now = now.Add(time.Hour)
+	refreshed := make(chan error)
+	go func() {
+		_, err := keySet.Keyfunc(token("old"))
+		refreshed <- err
+	}()
+	<-started
+	_, err = keySet.Keyfunc(token("old"))
+	assert.NoError(t, err)
+
+	waited := make(chan error)
+	go func() {
+		_, err := keySet.Keyfunc(token("new"))
+		waited <- err
+	}()
+	close(release)
+	assert.NoError(t, <-waited)
+	assert.Error(t, <-refreshed)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestFileKeySet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	edKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, os.WriteFile(path, []byte(`{"keys": [{"kty": "OKP", "crv": "Ed25519", "x": "`+encode(edKey)+`"}]}`), 0o600))
+
+	keySet, err := NewFileKeySet(path)
+	assert.NoError(t, err)
+	// tokens without kid use the only key
+	key, err := keySet.Keyfunc(&jwt.Token{Header: map[string]any{}})
+	assert.NoError(t, err)
+	assert.True(t, edKey.Equal(key))
+
+	_, err = NewFileKeySet(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
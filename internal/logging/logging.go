@@ -0,0 +1,203 @@
+// Structured logging of the proxy.
+//
+// Log records are written as text or json, secrets are redacted, and the request
+// id, client and trace of the context are added to every record logged with a context.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// HTTP header carrying the request id
+const RequestIdHeader = "X-Request-ID"
+
+// Attribute the request id is logged as
+const requestIdKey = "requestId"
+
+type requestIdContextKey struct{}
+
+// Attach a request id to the context, it's added to all records logged with the context
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	if requestId == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIdContextKey{}, requestId)
+}
+
+// The request id of the context, or an empty string
+func RequestId(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestId, _ := ctx.Value(requestIdContextKey{}).(string)
+	return requestId
+}
+
+// Attribute the client of a request is logged as
+const clientKey = "client"
+
+type clientContextKey struct{}
+
+// Attach the identity of the client, eg. from its certificate, to the context. It's added to
+// all records logged with the context.
+func WithClient(ctx context.Context, client string) context.Context {
+	if client == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// The identity of the client of the context, or an empty string
+func Client(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	client, _ := ctx.Value(clientContextKey{}).(string)
+	return client
+}
+
+// Generate a random request id
+func NewRequestId() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Whether a request id sent by a client can be used. Invalid ids are replaced by a new one.
+func IsValidRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > 128 {
+		return false
+	}
+	for _, c := range requestId {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse a log level name: debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("invalid log level '%s', expected 'debug', 'info', 'warn' or 'error'", name)
+	}
+}
+
+// Construct a handler writing records in the given format, with secrets redacted
+// and the request id and trace of the context added
+func NewHandler(w io.Writer, format string, level slog.Leveler) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr}
+	switch format {
+	case FormatText:
+		return contextHandler{slog.NewTextHandler(w, opts)}, nil
+	case FormatJSON:
+		return contextHandler{slog.NewJSONHandler(w, opts)}, nil
+	default:
+		return nil, fmt.Errorf("invalid log format '%s', expected '%s' or '%s'", format, FormatText, FormatJSON)
+	}
+}
+
+// Handler adding the request id, the client and the trace of the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	// some middlewares call Handle directly without checking the level
+	if !h.Enabled(ctx, record.Level) {
+		return nil
+	}
+	if requestId := RequestId(ctx); requestId != "" {
+		record.AddAttrs(slog.String(requestIdKey, requestId))
+	}
+	if client := Client(ctx); client != "" {
+		record.AddAttrs(slog.String(clientKey, client))
+	}
+	if ctx != nil {
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			record.AddAttrs(slog.String("traceId", spanContext.TraceID().String()), slog.String("spanId", spanContext.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+const redacted = "[REDACTED]"
+
+// Attribute keys whose values are always redacted, compared in lower case without separators
+var sensitiveKeys = []string{"token", "password", "secret", "authorization", "apikey", "cookie"}
+
+// Secrets embedded in strings, eg. in error messages or urls
+var sensitivePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[^\s"',]+`),
+	regexp.MustCompile(`(?i)((?:access_?token|api_?key|token|password|secret|client_secret)["']?\s*[:=]\s*["']?)[^\s"'&,}]+`),
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(key))
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove secrets from a string
+func Redact(s string) string {
+	for _, pattern := range sensitivePatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+redacted)
+	}
+	return s
+}
+
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindGroup {
+		return attr
+	}
+	if isSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(attr.Value.String()))
+	case slog.KindAny:
+		switch v := attr.Value.Any().(type) {
+		case error:
+			return slog.String(attr.Key, Redact(v.Error()))
+		case []byte:
+			return slog.String(attr.Key, Redact(string(v)))
+		}
+	}
+	return attr
+}
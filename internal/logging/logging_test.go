@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRedact(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"Authorization: Bearer abc.def-ghi", "Authorization: Bearer [REDACTED]"},
+		{"GET /api/v3/datasets?access_token=abc123&filter=x", "GET /api/v3/datasets?access_token=[REDACTED]&filter=x"},
+		{`{"token": "abc123", "pid": "p1"}`, `{"token": "[REDACTED]", "pid": "p1"}`},
+		{"password=hunter2", "password=[REDACTED]"},
+		{"nothing secret here", "nothing secret here"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, Redact(c.input))
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, FormatJSON, slog.LevelInfo)
+	assert.NoError(t, err)
+	logger := slog.New(handler)
+
+	ctx := WithClient(WithRequestId(context.Background(), "req-1"), "ingestor")
+	logger.InfoContext(ctx, "request",
+		"scicatToken", "abc123",
+		"SciCat-API-Key", "abc123",
+		"error", errors.New("login failed for Bearer abc123"),
+		"jobId", "job-1",
+	)
+	logger.DebugContext(ctx, "hidden")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 1)
+	record := map[string]any{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "req-1", record["requestId"])
+	assert.Equal(t, "ingestor", record["client"])
+	assert.Equal(t, "[REDACTED]", record["scicatToken"])
+	assert.Equal(t, "[REDACTED]", record["SciCat-API-Key"])
+	assert.Equal(t, "login failed for Bearer [REDACTED]", record["error"])
+	assert.Equal(t, "job-1", record["jobId"])
+
+	// records without a request id, and records handled without checking the level
+	buf.Reset()
+	logger.Info("no request")
+	_ = handler.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelDebug, "ignored", 0))
+	assert.NotContains(t, buf.String(), "requestId")
+	assert.NotContains(t, buf.String(), "ignored")
+
+	_, err = NewHandler(&buf, "xml", slog.LevelInfo)
+	assert.Error(t, err)
+}
+
+func TestRequestId(t *testing.T) {
+	assert.Equal(t, "", RequestId(context.Background()))
+	assert.Equal(t, "", RequestId(WithRequestId(context.Background(), "")))
+	assert.Equal(t, "abc", RequestId(WithRequestId(context.Background(), "abc")))
+
+	id := NewRequestId()
+	assert.Len(t, id, 32)
+	assert.NotEqual(t, id, NewRequestId())
+
+	assert.True(t, IsValidRequestId(id))
+	assert.True(t, IsValidRequestId("7f3c-request_1"))
+	assert.False(t, IsValidRequestId(""))
+	assert.False(t, IsValidRequestId("with space"))
+	assert.False(t, IsValidRequestId("line\nbreak"))
+	assert.False(t, IsValidRequestId(strings.Repeat("a", 129)))
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("Warning")
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+	level, err = ParseLevel("debug")
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level)
+	_, err = ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestHandlerTrace(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, FormatJSON, slog.LevelInfo)
+	assert.NoError(t, err)
+
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId}))
+	slog.New(handler).InfoContext(ctx, "polled")
+
+	record := map[string]any{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["traceId"])
+	assert.Equal(t, "00f067aa0ba902b7", record["spanId"])
+}
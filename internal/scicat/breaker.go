@@ -0,0 +1,67 @@
+package scicat
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Circuit breaker failing SciCat requests fast after repeated failures. Once the
+// cooldown expired, a single request is let through; it closes the breaker again
+// if it succeeds, and restarts the cooldown otherwise.
+type circuitBreaker struct {
+	mutex     sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	// set while the request testing whether SciCat recovered is running
+	probing bool
+	now     func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// Whether a request may be sent. Every allowed request must be followed by record or release.
+func (b *circuitBreaker) allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// Record the outcome of a request
+func (b *circuitBreaker) record(failed bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probing = false
+	if !failed {
+		if b.failures >= b.threshold && b.threshold > 0 {
+			slog.Info("SciCat recovered, closing circuit breaker")
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold {
+		if b.failures == b.threshold {
+			slog.Warn("SciCat requests keep failing, opening circuit breaker", "failures", b.failures, "cooldown", b.cooldown)
+		}
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// Release an allowed request without an outcome, eg. if it was cancelled
+func (b *circuitBreaker) release() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probing = false
+}
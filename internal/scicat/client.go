@@ -0,0 +1,236 @@
+// Interact with SciCat backend service
+package scicat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/util"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Backoff between the attempts of a retried request
+var retryBackoff = util.Backoff{Initial: 500 * time.Millisecond, Max: 5 * time.Second}
+
+// Error of a SciCat call that can't be completed because SciCat failed repeatedly
+var ErrUnavailable = errors.New("SciCat is unavailable, too many requests failed")
+
+// Error of a job call for a job that doesn't exist
+var ErrJobNotFound = errors.New("job not found")
+
+// Client of the SciCat backend, shared by all calls to SciCat
+//
+// Error() messages are intended to be returned to users. Errors are *DetailedError,
+// with details intended for logging, or *HttpError if SciCat responded with an error.
+type Client struct {
+	url        string
+	httpClient *http.Client
+	// the same connections without tracing, for the calls of scicat-cli
+	untracedClient *http.Client
+	maxAttempts    int
+	breaker        *circuitBreaker
+}
+
+// Allows additional details to be attached to an error
+type DetailedError struct {
+	Message string
+	Details string
+	Err     error
+}
+
+var _ error = (*DetailedError)(nil)
+
+func (e DetailedError) Error() string {
+	msg := e.Message
+	if e.Details != "" {
+		msg += ": " + e.Details
+	}
+	if e.Err != nil {
+		msg += "\n" + e.Err.Error()
+	}
+	return msg
+}
+
+func (e DetailedError) Unwrap() error {
+	return e.Err
+}
+
+// Error response of SciCat
+type HttpError struct {
+	StatusCode int
+	DetailedError
+}
+
+var _ error = (*HttpError)(nil)
+
+// Construct a client of the SciCat instance at scicatUrl, connecting through transport
+func NewClient(scicatUrl string, conf config.ScicatConfig, transport http.RoundTripper) *Client {
+	timeout := time.Duration(conf.Timeout) * time.Second
+	return &Client{
+		url: scicatUrl,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: otelhttp.NewTransport(transport),
+		},
+		untracedClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+		maxAttempts: max(int(conf.MaxAttempts), 1),
+		breaker:     newCircuitBreaker(int(conf.BreakerThreshold), time.Duration(conf.BreakerCooldown)*time.Second),
+	}
+}
+
+// Base url of the SciCat instance
+func (c *Client) Url() string {
+	return c.url
+}
+
+// HTTP client for the scicat-cli helpers, which don't pass a context to trace their requests in
+func (c *Client) HttpClient() *http.Client {
+	return c.untracedClient
+}
+
+// A request to the SciCat API
+type request struct {
+	method string
+	// path below the base url, eg. "api", "v4", "jobs"
+	path  []string
+	query url.Values
+	token string
+	// marshalled to json if not nil
+	body   any
+	header http.Header
+	// only idempotent requests are retried
+	idempotent bool
+	// describes the failed call in errors
+	errorMessage string
+}
+
+// Send a request and decode the json response into result, if result isn't nil.
+// Idempotent requests are retried with backoff on network errors and server errors.
+func (c *Client) do(ctx context.Context, req request, result any) error {
+	reqUrl, err := url.JoinPath(c.url, req.path...)
+	if err != nil {
+		return &DetailedError{Message: req.errorMessage, Details: "couldn't create request url", Err: err}
+	}
+	if len(req.query) > 0 {
+		reqUrl += "?" + req.query.Encode()
+	}
+	var body []byte
+	if req.body != nil {
+		body, err = json.Marshal(req.body)
+		if err != nil {
+			return &DetailedError{Message: req.errorMessage, Details: "couldn't encode request body", Err: err}
+		}
+	}
+
+	attempts := 1
+	if req.idempotent {
+		attempts = c.maxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		respBody, err := c.send(ctx, req, reqUrl, body)
+		if err == nil {
+			if result == nil {
+				return nil
+			}
+			if err := json.Unmarshal(respBody, result); err != nil {
+				return &DetailedError{Message: req.errorMessage, Details: "couldn't decode the response of SciCat", Err: err}
+			}
+			return nil
+		}
+		if attempt >= attempts || !isTransient(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := retryBackoff.Delay(attempt)
+		slog.DebugContext(ctx, "SciCat request failed, retrying", "method", req.method, "url", reqUrl, "attempt", attempt, "retryIn", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Send a single attempt of a request, returns the body of a successful response
+func (c *Client) send(ctx context.Context, req request, reqUrl string, body []byte) ([]byte, error) {
+	if !c.breaker.allow() {
+		return nil, &DetailedError{Message: req.errorMessage, Err: ErrUnavailable}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, reqUrl, bytes.NewReader(body))
+	if err != nil {
+		c.breaker.release()
+		return nil, &DetailedError{Message: req.errorMessage, Details: "couldn't create request", Err: err}
+	}
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+req.token)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			c.breaker.release()
+		} else {
+			c.breaker.record(true)
+		}
+		return nil, &DetailedError{Message: req.errorMessage, Details: "the request to SciCat failed", Err: err}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.breaker.record(true)
+		return nil, &DetailedError{Message: req.errorMessage, Details: "couldn't read the response of SciCat", Err: err}
+	}
+	c.breaker.record(resp.StatusCode >= 500)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &HttpError{
+			StatusCode: resp.StatusCode,
+			DetailedError: DetailedError{
+				Message: req.errorMessage,
+				Details: fmt.Sprintf("status: '%d', body: '%s'", resp.StatusCode, string(respBody)),
+			},
+		}
+	}
+	return respBody, nil
+}
+
+// Whether a failed request may succeed when it's retried
+func isTransient(err error) bool {
+	if errors.Is(err, ErrUnavailable) {
+		return false
+	}
+	if httpErr := asHttpError(err); httpErr != nil {
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// The error response of SciCat in err, or nil
+func asHttpError(err error) *HttpError {
+	var httpErr *HttpError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package scicat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	backoff := retryBackoff
+	retryBackoff = util.Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+	t.Cleanup(func() { retryBackoff = backoff })
+	return NewClient(server.URL+"/", config.NewScicatConfig(), http.DefaultTransport)
+}
+
+func TestRetryIdempotentRequests(t *testing.T) {
+	calls := atomic.Int32{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/api/v3/datasets/prefix%2Fpid", r.URL.EscapedPath())
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"pid": "prefix/pid", "ownerGroup": "group"}`))
+	})
+
+	dataset, err := client.GetDataset(context.Background(), "token", "prefix/pid")
+	assert.NoError(t, err)
+	assert.Equal(t, "group", dataset.OwnerGroup)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestNoRetry(t *testing.T) {
+	calls := atomic.Int32{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	// job creation isn't idempotent
+	_, err := client.CreateJob(context.Background(), "token", NewJob{Type: "globus_transfer_job"})
+	var httpErr *HttpError
+	assert.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// client errors aren't retried
+	_, err = client.GetDataset(context.Background(), "token", "pid")
+	assert.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, "the dataset with the given pid does not exist or you don't have access rights to it", httpErr.Message)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestJobNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message": "Invalid job id"}`))
+	})
+
+	_, err := client.GetJobById(context.Background(), "token", "job1")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	_, err = client.UpdateJob(context.Background(), "token", "job1", JobPatch{StatusCode: "002"})
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	err = client.DeleteJob(context.Background(), "token", "job1")
+	assert.False(t, errors.Is(err, ErrJobNotFound))
+
+	// invalid updates of existing jobs aren't dropped as updates of unknown jobs
+	client = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message": "jobResultObject must be an object"}`))
+	})
+	_, err = client.UpdateJob(context.Background(), "token", "job1", JobPatch{StatusCode: "002"})
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrJobNotFound))
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	assert.True(t, breaker.allow())
+	breaker.record(true)
+	assert.True(t, breaker.allow())
+	breaker.record(true)
+	assert.False(t, breaker.allow())
+
+	// a single request tests whether SciCat recovered
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.allow())
+	assert.False(t, breaker.allow())
+	breaker.record(true)
+	assert.False(t, breaker.allow())
+
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.allow())
+	breaker.record(false)
+	assert.True(t, breaker.allow())
+	assert.True(t, breaker.allow())
+}
+
+func TestCircuitBreakerFailsFast(t *testing.T) {
+	calls := atomic.Int32{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := client.GetJobList(context.Background(), "token", "{}")
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, http.StatusInternalServerError, asHttpError(err).StatusCode)
+
+	_, err = client.GetJobList(context.Background(), "token", "{}")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, int32(5), calls.Load())
+}
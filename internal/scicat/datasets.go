@@ -0,0 +1,64 @@
+package scicat
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Get the SciCat user identity associated with the provided API key
+func (c *Client) GetUserIdentity(ctx context.Context, token string) (user User, err error) {
+	ctx, span := tracing.Start(ctx, "scicat.GetUserIdentity")
+	defer func() { tracing.End(span, err) }()
+
+	err = c.do(ctx, request{
+		method:       http.MethodGet,
+		path:         []string{"api", "v3", "users", "my", "identity"},
+		token:        token,
+		idempotent:   true,
+		errorMessage: "unable to authenticate SciCat user",
+	}, &user)
+	if httpErr := asHttpError(err); httpErr != nil && httpErr.StatusCode < 500 {
+		httpErr.Message = "unable to authenticate SciCat user. The access token provided with the request is invalid"
+	}
+	if err != nil {
+		return User{}, err
+	}
+
+	user.ScicatToken = token
+	return user, nil
+}
+
+func (c *Client) GetDataset(ctx context.Context, token string, datasetPid string) (dataset ScicatDataset, err error) {
+	ctx, span := tracing.Start(ctx, "scicat.GetDataset", attribute.String("scicat.pid", datasetPid))
+	defer func() { tracing.End(span, err) }()
+
+	err = c.do(ctx, request{
+		method:       http.MethodGet,
+		path:         []string{"api", "v3", "datasets", url.PathEscape(datasetPid)},
+		token:        token,
+		idempotent:   true,
+		errorMessage: "couldn't fetch the dataset from SciCat",
+	}, &dataset)
+	if httpErr := asHttpError(err); httpErr != nil && httpErr.StatusCode < 500 {
+		httpErr.Message = "the dataset with the given pid does not exist or you don't have access rights to it"
+	}
+	return dataset, err
+}
+
+func (c *Client) GetOrigDatablocks(ctx context.Context, token string, datasetPid string) (origDatablocks []ScicatOrigDatablock, err error) {
+	ctx, span := tracing.Start(ctx, "scicat.GetOrigDatablocks", attribute.String("scicat.pid", datasetPid))
+	defer func() { tracing.End(span, err) }()
+
+	err = c.do(ctx, request{
+		method:       http.MethodGet,
+		path:         []string{"api", "v3", "datasets", url.PathEscape(datasetPid), "origdatablocks"},
+		token:        token,
+		idempotent:   true,
+		errorMessage: "couldn't fetch the datablocks of the dataset from SciCat",
+	}, &origDatablocks)
+	return origDatablocks, err
+}
@@ -0,0 +1,177 @@
+package scicat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/logging"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Body of a job creation
+type NewJob struct {
+	Type         string         `json:"type"`
+	JobParams    jobs.JobParams `json:"jobParams"`
+	OwnerUser    string         `json:"ownerUser,omitempty"`
+	OwnerGroup   string         `json:"ownerGroup,omitempty"`
+	ContactEmail string         `json:"contactEmail,omitempty"`
+}
+
+// Body of a job update. The fields are replaced, so sending an update again has no further effect.
+type JobPatch struct {
+	StatusCode      string               `json:"statusCode,omitempty"`
+	StatusMessage   string               `json:"statusMessage,omitempty"`
+	JobResultObject jobs.JobResultObject `json:"jobResultObject,omitempty"`
+}
+
+type fullFacetReplyElement struct {
+	All []fullFacetInternalObject `json:"all"`
+}
+
+type fullFacetInternalObject struct {
+	TotalSets uint `json:"totalSets"`
+}
+
+// Wrap ErrJobNotFound into the error of a 404 response, or of a 400 response mentioning
+// one of the messages, as SciCat responds to some calls for unknown jobs with 400
+func jobNotFound(err error, messages ...string) error {
+	httpErr := asHttpError(err)
+	if httpErr == nil {
+		return err
+	}
+	if httpErr.StatusCode == http.StatusNotFound {
+		httpErr.Err = ErrJobNotFound
+		return err
+	}
+	if httpErr.StatusCode != http.StatusBadRequest {
+		return err
+	}
+	for _, msg := range messages {
+		if strings.Contains(httpErr.Details, msg) {
+			httpErr.Err = ErrJobNotFound
+		}
+	}
+	return err
+}
+
+func (c *Client) GetJobList(ctx context.Context, token string, filter string) (jobList []jobs.ScicatJob, err error) {
+	ctx, span := tracing.Start(ctx, "scicat.GetJobList")
+	defer func() { tracing.End(span, err) }()
+
+	err = c.do(ctx, request{
+		method:       http.MethodGet,
+		path:         []string{"api", "v4", "jobs"},
+		query:        url.Values{"filter": {filter}},
+		token:        token,
+		idempotent:   true,
+		errorMessage: "getting transfer job list failed",
+	}, &jobList)
+	return jobList, err
+}
+
+func (c *Client) GetJobCount(ctx context.Context, token string, filter string) (_ uint, err error) {
+	ctx, span := tracing.Start(ctx, "scicat.GetJobCount")
+	defer func() { tracing.End(span, err) }()
+
+	result := []fullFacetReplyElement{}
+	err = c.do(ctx, request{
+		method:       http.MethodGet,
+		path:         []string{"api", "v4", "jobs", "fullfacet"},
+		query:        url.Values{"filter": {filter}},
+		token:        token,
+		idempotent:   true,
+		errorMessage: "getting transfer job count failed",
+	}, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(result) != 1 {
+		return 0, fmt.Errorf("server replied with an unexpected number of reply objects in its response")
+	}
+	if len(result[0].All) != 1 {
+		return 0, fmt.Errorf("the 'All' element in the server response contains an unexpected number of objects")
+	}
+	return result[0].All[0].TotalSets, nil
+}
+
+// Get a job. Fails with ErrJobNotFound if the job doesn't exist.
+func (c *Client) GetJobById(ctx context.Context, token string, jobId string) (job jobs.ScicatJob, err error) {
+	ctx, span := tracing.Start(ctx, "scicat.GetJobById", attribute.String("scicat.job_id", jobId))
+	defer func() { tracing.End(span, err) }()
+
+	err = c.do(ctx, request{
+		method:       http.MethodGet,
+		path:         []string{"api", "v4", "jobs", url.PathEscape(jobId)},
+		token:        token,
+		idempotent:   true,
+		errorMessage: "getting the job failed",
+	}, &job)
+	if httpErr := asHttpError(err); httpErr != nil && httpErr.StatusCode == http.StatusForbidden {
+		httpErr.Message = "user doesn't have the right to access this job or user credentials are invalid"
+	}
+	return job, jobNotFound(err, "Invalid job id")
+}
+
+// Create a job. It's not retried, as a retry could create a second job.
+func (c *Client) CreateJob(ctx context.Context, token string, job NewJob) (created jobs.ScicatJob, err error) {
+	ctx, span := tracing.Start(ctx, "scicat.CreateJob", attribute.String("scicat.job_type", job.Type))
+	defer func() { tracing.End(span, err) }()
+
+	header := http.Header{}
+	if job.JobParams.RequestId != "" {
+		header.Set(logging.RequestIdHeader, job.JobParams.RequestId)
+	}
+	err = c.do(ctx, request{
+		method:       http.MethodPost,
+		path:         []string{"api", "v4", "jobs"},
+		token:        token,
+		body:         job,
+		header:       header,
+		errorMessage: "creating the job failed",
+	}, &created)
+	if httpErr := asHttpError(err); httpErr != nil && httpErr.StatusCode == http.StatusUnauthorized {
+		httpErr.Message = "authentication failed: user is not logged in"
+	}
+	return created, err
+}
+
+// Update the status of a job. Fails with ErrJobNotFound if the job doesn't exist.
+func (c *Client) UpdateJob(ctx context.Context, token string, jobId string, patch JobPatch) (job jobs.ScicatJob, err error) {
+	ctx, span := tracing.Start(ctx, "scicat.UpdateJob",
+		attribute.String("scicat.job_id", jobId),
+		attribute.String("scicat.status_code", patch.StatusCode),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	err = c.do(ctx, request{
+		method:       http.MethodPatch,
+		path:         []string{"api", "v4", "jobs", url.PathEscape(jobId)},
+		token:        token,
+		body:         patch,
+		idempotent:   true,
+		errorMessage: "cannot patch job",
+	}, &job)
+	// SciCat responds with 400 to updates of unknown jobs, other 400s are invalid patches
+	return job, jobNotFound(err, "Invalid job id")
+}
+
+// Delete a job. Fails with ErrJobNotFound if the job doesn't exist.
+func (c *Client) DeleteJob(ctx context.Context, token string, jobId string) (err error) {
+	ctx, span := tracing.Start(ctx, "scicat.DeleteJob", attribute.String("scicat.job_id", jobId))
+	defer func() { tracing.End(span, err) }()
+
+	err = c.do(ctx, request{
+		method:       http.MethodDelete,
+		path:         []string{"api", "v4", "jobs", url.PathEscape(jobId)},
+		token:        token,
+		idempotent:   true,
+		errorMessage: "couldn't delete job",
+	}, nil)
+	return jobNotFound(err, "doesn't exist")
+}
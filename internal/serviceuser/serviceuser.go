@@ -17,9 +17,10 @@ type ScicatServiceUser struct {
 	scicatToken *string
 	expiry      *time.Time
 	mutex       *sync.Mutex
+	httpClient  *http.Client
 }
 
-func CreateServiceUser(scicatUrl string, username string, password string) (ScicatServiceUser, error) {
+func CreateServiceUser(scicatUrl string, username string, password string, httpClient *http.Client) (ScicatServiceUser, error) {
 	var emptyString = ""
 	var zeroTime = time.Time{}
 	var mutex sync.Mutex
@@ -30,6 +31,7 @@ func CreateServiceUser(scicatUrl string, username string, password string) (Scic
 		scicatToken: &emptyString,
 		expiry:      &zeroTime,
 		mutex:       &mutex,
+		httpClient:  httpClient,
 	}
 	return serviceUser, serviceUser.refreshToken()
 }
@@ -47,7 +49,7 @@ func (su *ScicatServiceUser) GetToken() (string, error) {
 }
 
 func (su *ScicatServiceUser) refreshToken() error {
-	user, _, err := datasetUtils.AuthenticateUser(http.DefaultClient, *su.scicatUrl+"api/v3", *su.Username, *su.Password, false)
+	user, _, err := datasetUtils.AuthenticateUser(su.httpClient, *su.scicatUrl+"api/v3", *su.Username, *su.Password, false)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,270 @@
+// Local persistent state of the proxy.
+//
+// The store is the source of truth for transfers handled by this instance,
+// idempotency keys and scheduled work. SciCat jobs are a projection of the
+// transfers kept in sync by the task pool.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	transfersBucket   = []byte("transfers")
+	idempotencyBucket = []byte("idempotency")
+	scheduledBucket   = []byte("scheduled")
+)
+
+var ErrNotFound = errors.New("not found in store")
+
+// How long a reservation blocks retries of a request that never completed it, eg. because
+// the proxy crashed while handling the request
+const pendingIdempotencyTTL = 5 * time.Minute
+
+// A transfer handled by the proxy
+type Transfer struct {
+	ScicatJobId  string   `json:"scicatJobId"`
+	GlobusTaskId string   `json:"globusTaskId"`
+	DatasetPids  []string `json:"datasetPids"`
+	jobs.ArchivalJobInfo
+	jobs.TransferParams
+	ProxyVersion string         `json:"proxyVersion,omitempty"`
+	RequestId    string         `json:"requestId,omitempty"`
+	TraceParent  string         `json:"traceParent,omitempty"`
+	Progress     Progress       `json:"progress"`
+	Status       jobs.JobStatus `json:"status"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+}
+
+// Progress of a transfer as last reported to SciCat
+type Progress struct {
+	BytesTransferred uint `json:"bytesTransferred"`
+	FilesTransferred uint `json:"filesTransferred"`
+	FilesTotal       uint `json:"filesTotal"`
+}
+
+// Whether the transfer has reached a final state and doesn't need monitoring anymore
+func (t Transfer) IsDone() bool {
+	switch t.Status {
+	case jobs.Finished, jobs.Failed, jobs.Cancelled, jobs.SubmissionFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+type idempotencyEntry struct {
+	ScicatJobId string    `json:"scicatJobId"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Whether the key can be reused. Reservations without a job expire after a short time.
+func (e idempotencyEntry) expired(ttl time.Duration) bool {
+	if e.ScicatJobId == "" {
+		ttl = min(ttl, pendingIdempotencyTTL)
+	}
+	return time.Since(e.CreatedAt) >= ttl
+}
+
+type Store struct {
+	db *bolt.DB
+}
+
+// Open or create the store at the given path. Only one process can open the store at a time.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open state store \"%s\": %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{transfersBucket, idempotencyBucket, scheduledBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Insert or replace a transfer
+func (s *Store) PutTransfer(transfer Transfer) error {
+	now := time.Now()
+	if transfer.CreatedAt.IsZero() {
+		transfer.CreatedAt = now
+	}
+	transfer.UpdatedAt = now
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(transfersBucket), transfer.ScicatJobId, transfer)
+	})
+}
+
+func (s *Store) GetTransfer(scicatJobId string) (Transfer, error) {
+	var transfer Transfer
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return getJSON(tx.Bucket(transfersBucket), scicatJobId, &transfer)
+	})
+	return transfer, err
+}
+
+func (s *Store) UpdateTransferStatus(scicatJobId string, status jobs.JobStatus) error {
+	return s.updateTransfer(scicatJobId, func(transfer *Transfer) { transfer.Status = status })
+}
+
+func (s *Store) UpdateTransferProgress(scicatJobId string, progress Progress) error {
+	return s.updateTransfer(scicatJobId, func(transfer *Transfer) { transfer.Progress = progress })
+}
+
+func (s *Store) updateTransfer(scicatJobId string, update func(*Transfer)) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(transfersBucket)
+		var transfer Transfer
+		if err := getJSON(bucket, scicatJobId, &transfer); err != nil {
+			return err
+		}
+		update(&transfer)
+		transfer.UpdatedAt = time.Now()
+		return putJSON(bucket, scicatJobId, transfer)
+	})
+}
+
+func (s *Store) DeleteTransfer(scicatJobId string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(transfersBucket).Delete([]byte(scicatJobId))
+	})
+}
+
+// List all transfers for which the filter returns true. A nil filter returns all transfers.
+func (s *Store) ListTransfers(filter func(Transfer) bool) ([]Transfer, error) {
+	transfers := []Transfer{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(transfersBucket).ForEach(func(k, v []byte) error {
+			var transfer Transfer
+			if err := json.Unmarshal(v, &transfer); err != nil {
+				return fmt.Errorf("corrupt transfer record \"%s\": %w", k, err)
+			}
+			if filter == nil || filter(transfer) {
+				transfers = append(transfers, transfer)
+			}
+			return nil
+		})
+	})
+	return transfers, err
+}
+
+// Reserve an idempotency key for a new request.
+//
+// If the key was already used within the ttl, the job id of the earlier request
+// is returned and reserved is false. The job id is empty while the earlier
+// request is still in progress. Expired keys and reservations of requests that
+// didn't complete within a few minutes are reused.
+func (s *Store) ReserveIdempotencyKey(key string, ttl time.Duration) (scicatJobId string, reserved bool, err error) {
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(idempotencyBucket)
+		var entry idempotencyEntry
+		err := getJSON(bucket, key, &entry)
+		if err == nil && !entry.expired(ttl) {
+			scicatJobId = entry.ScicatJobId
+			return nil
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		reserved = true
+		return putJSON(bucket, key, idempotencyEntry{CreatedAt: time.Now()})
+	})
+	return scicatJobId, reserved, err
+}
+
+// Associate a reserved idempotency key with the job created by the request
+func (s *Store) CompleteIdempotencyKey(key string, scicatJobId string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(idempotencyBucket), key, idempotencyEntry{ScicatJobId: scicatJobId, CreatedAt: time.Now()})
+	})
+}
+
+// Release a reserved idempotency key after the request failed, so it can be retried
+func (s *Store) ReleaseIdempotencyKey(key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(idempotencyBucket).Delete([]byte(key))
+	})
+}
+
+// Remove idempotency keys older than the ttl, and reservations of requests that didn't complete
+func (s *Store) PruneIdempotencyKeys(ttl time.Duration) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(idempotencyBucket)
+		expired := [][]byte{}
+		err := bucket.ForEach(func(k, v []byte) error {
+			var entry idempotencyEntry
+			if err := json.Unmarshal(v, &entry); err != nil || entry.expired(ttl) {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Load scheduled work stored under the given name into v.
+// Returns false if nothing was stored yet.
+func (s *Store) LoadScheduled(name string, v any) (bool, error) {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return getJSON(tx.Bucket(scheduledBucket), name, v)
+	})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Replace the scheduled work stored under the given name
+func (s *Store) SaveScheduled(name string, v any) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(scheduledBucket), name, v)
+	})
+}
+
+func putJSON(bucket *bolt.Bucket, key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), value)
+}
+
+func getJSON(bucket *bolt.Bucket, key string, v any) error {
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return ErrNotFound
+	}
+	return json.Unmarshal(value, v)
+}
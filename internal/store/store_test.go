@@ -0,0 +1,117 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func openTestStore(t *testing.T) *Store {
+	st, err := Open(filepath.Join(t.TempDir(), "state.db"))
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+func TestTransfers(t *testing.T) {
+	st := openTestStore(t)
+
+	assert.Nil(t, st.PutTransfer(Transfer{ScicatJobId: "job1", GlobusTaskId: "task1", DatasetPids: []string{"pid1"}, Status: jobs.Transferring}))
+	assert.Nil(t, st.PutTransfer(Transfer{ScicatJobId: "job2", GlobusTaskId: "task2", Status: jobs.Transferring}))
+	assert.Nil(t, st.UpdateTransferStatus("job2", jobs.Finished))
+	assert.Nil(t, st.UpdateTransferProgress("job2", Progress{BytesTransferred: 10, FilesTransferred: 1, FilesTotal: 2}))
+
+	transfer, err := st.GetTransfer("job1")
+	assert.Nil(t, err)
+	assert.Equal(t, "task1", transfer.GlobusTaskId)
+	assert.False(t, transfer.CreatedAt.IsZero())
+	transfer, err = st.GetTransfer("job2")
+	assert.Nil(t, err)
+	assert.Equal(t, jobs.Finished, transfer.Status)
+	assert.Equal(t, uint(10), transfer.Progress.BytesTransferred)
+
+	unfinished, err := st.ListTransfers(func(t Transfer) bool { return !t.IsDone() })
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(unfinished))
+	assert.Equal(t, "job1", unfinished[0].ScicatJobId)
+
+	assert.Nil(t, st.DeleteTransfer("job1"))
+	_, err = st.GetTransfer("job1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, st.UpdateTransferStatus("job1", jobs.Failed), ErrNotFound)
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	st := openTestStore(t)
+
+	jobId, reserved, err := st.ReserveIdempotencyKey("user/key", time.Hour)
+	assert.Nil(t, err)
+	assert.True(t, reserved)
+	assert.Equal(t, "", jobId)
+
+	// in progress
+	jobId, reserved, err = st.ReserveIdempotencyKey("user/key", time.Hour)
+	assert.Nil(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "", jobId)
+
+	assert.Nil(t, st.CompleteIdempotencyKey("user/key", "job1"))
+	jobId, reserved, err = st.ReserveIdempotencyKey("user/key", time.Hour)
+	assert.Nil(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "job1", jobId)
+
+	// expired keys can be reused
+	_, reserved, err = st.ReserveIdempotencyKey("user/key", 0)
+	assert.Nil(t, err)
+	assert.True(t, reserved)
+
+	assert.Nil(t, st.ReleaseIdempotencyKey("user/key"))
+	assert.Nil(t, st.PruneIdempotencyKeys(0))
+}
+
+func TestAbandonedIdempotencyKeys(t *testing.T) {
+	st := openTestStore(t)
+
+	// reservation left behind by a request that never completed
+	abandoned := idempotencyEntry{CreatedAt: time.Now().Add(-pendingIdempotencyTTL)}
+	assert.Nil(t, st.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(idempotencyBucket), "user/abandoned", abandoned)
+	}))
+	_, reserved, err := st.ReserveIdempotencyKey("user/abandoned", 24*time.Hour)
+	assert.Nil(t, err)
+	assert.True(t, reserved)
+
+	assert.Nil(t, st.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(idempotencyBucket), "user/abandoned", abandoned)
+	}))
+	assert.Nil(t, st.CompleteIdempotencyKey("user/completed", "job1"))
+	assert.Nil(t, st.PruneIdempotencyKeys(24*time.Hour))
+	err = st.db.View(func(tx *bolt.Tx) error {
+		return getJSON(tx.Bucket(idempotencyBucket), "user/abandoned", &idempotencyEntry{})
+	})
+	assert.ErrorIs(t, err, ErrNotFound)
+	jobId, reserved, err := st.ReserveIdempotencyKey("user/completed", 24*time.Hour)
+	assert.Nil(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, "job1", jobId)
+}
+
+func TestScheduled(t *testing.T) {
+	st := openTestStore(t)
+
+	var items []string
+	found, err := st.LoadScheduled("work", &items)
+	assert.Nil(t, err)
+	assert.False(t, found)
+
+	assert.Nil(t, st.SaveScheduled("work", []string{"a", "b"}))
+	found, err = st.LoadScheduled("work", &items)
+	assert.Nil(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []string{"a", "b"}, items)
+}
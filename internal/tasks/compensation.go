@@ -0,0 +1,134 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/globusclient"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+)
+
+const (
+	compensationsScheduledName = "compensations"
+	// Compensations are given up after this many failed attempts
+	compensationMaxAttempts = 10
+)
+
+// A globus task that must be cancelled because its submission failed
+type Compensation struct {
+	GlobusTaskId string    `json:"globusTaskId"`
+	ScicatJobId  string    `json:"scicatJobId"`
+	Attempts     int       `json:"attempts"`
+	LastError    string    `json:"lastError"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Durable list of compensations that failed during submission. They are retried by the reconciler,
+// or by Run if reconciliation is disabled.
+type CompensationQueue struct {
+	store   *store.Store
+	pending []Compensation
+	mutex   sync.Mutex
+}
+
+func NewCompensationQueue(st *store.Store) (*CompensationQueue, error) {
+	pending := []Compensation{}
+	if _, err := st.LoadScheduled(compensationsScheduledName, &pending); err != nil {
+		return nil, err
+	}
+	return &CompensationQueue{store: st, pending: pending}, nil
+}
+
+func (q *CompensationQueue) Add(c Compensation) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	q.pending = append(q.pending, c)
+	q.persist()
+}
+
+func (q *CompensationQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.pending)
+}
+
+// Retry the pending compensations at the given interval until the context is cancelled.
+// Cancelling interrupts a running retry, Run returns once it stopped.
+func (q *CompensationQueue) Run(ctx context.Context, globusClient globus.GlobusClient, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.Retry(ctx, globusClient)
+		}
+	}
+}
+
+// Attempt all pending compensations until ctx is cancelled. Returns the compensations that were
+// attempted, with LastError empty for the ones that succeeded.
+func (q *CompensationQueue) Retry(ctx context.Context, globusClient globus.GlobusClient) []Compensation {
+	q.mutex.Lock()
+	pending := append([]Compensation{}, q.pending...)
+	q.mutex.Unlock()
+
+	attempted := []Compensation{}
+	remaining := []Compensation{}
+	for _, c := range pending {
+		if ctx.Err() != nil {
+			// interrupted, eg. by a shutdown, the compensation isn't attempted
+			remaining = append(remaining, c)
+			continue
+		}
+		c.Attempts++
+		c.LastError = ""
+		if _, err := globusclient.CancelTask(ctx, globusClient, c.GlobusTaskId); err != nil {
+			c.LastError = err.Error()
+			if c.Attempts < compensationMaxAttempts {
+				remaining = append(remaining, c)
+			} else {
+				slog.ErrorContext(ctx, "Giving up cancelling globus task of failed submission", "globusTaskId", c.GlobusTaskId, "jobId", c.ScicatJobId, "attempts", c.Attempts, "error", err)
+			}
+		}
+		attempted = append(attempted, c)
+	}
+
+	retried := make(map[string]bool, len(pending))
+	for _, c := range pending {
+		retried[c.GlobusTaskId] = true
+	}
+	kept := make(map[string]Compensation, len(remaining))
+	for _, c := range remaining {
+		kept[c.GlobusTaskId] = c
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	// merge by task, the queue may have changed in the meantime
+	merged := []Compensation{}
+	for _, c := range q.pending {
+		if updated, ok := kept[c.GlobusTaskId]; ok {
+			merged = append(merged, updated)
+		} else if !retried[c.GlobusTaskId] {
+			merged = append(merged, c)
+		}
+	}
+	q.pending = merged
+	q.persist()
+	return attempted
+}
+
+// must be called with the mutex held
+func (q *CompensationQueue) persist() {
+	if err := q.store.SaveScheduled(compensationsScheduledName, q.pending); err != nil {
+		slog.Error("couldn't persist pending compensations", "error", err)
+	}
+}
@@ -0,0 +1,138 @@
+package tasks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Globus client whose task cancellations respond with the status returned by cancel
+func fakeGlobusClient(cancel func(taskId string) int) globus.GlobusClient {
+	return globus.HttpClientToGlobusClient(&http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		status := http.StatusNotFound
+		if taskId, ok := strings.CutSuffix(strings.TrimPrefix(req.URL.Path, "/v0.10/task/"), "/cancel"); ok && req.Method == http.MethodPost {
+			status = cancel(taskId)
+		}
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"code":"Canceled"}`)),
+			Request:    req,
+		}, nil
+	})})
+}
+
+func TestCompensationRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		pending []Compensation
+		// cancellations that fail
+		failing []string
+		// runs while the first cancellation is running
+		during func(*CompensationQueue, globus.GlobusClient)
+		// task ids still pending after the retry
+		remaining []string
+		attempts  map[string]int
+	}{
+		{
+			name:      "success",
+			pending:   []Compensation{{GlobusTaskId: "task1", Attempts: 1}},
+			remaining: []string{},
+		},
+		{
+			name:      "failure",
+			pending:   []Compensation{{GlobusTaskId: "task1", Attempts: 1}},
+			failing:   []string{"task1"},
+			remaining: []string{"task1"},
+			attempts:  map[string]int{"task1": 2},
+		},
+		{
+			name:      "max attempts",
+			pending:   []Compensation{{GlobusTaskId: "task1", Attempts: compensationMaxAttempts - 1}},
+			failing:   []string{"task1"},
+			remaining: []string{},
+		},
+		{
+			name:    "added during retry",
+			pending: []Compensation{{GlobusTaskId: "task1", Attempts: 1}, {GlobusTaskId: "task2", Attempts: 1}},
+			failing: []string{"task2"},
+			during: func(q *CompensationQueue, _ globus.GlobusClient) {
+				q.Add(Compensation{GlobusTaskId: "task3", Attempts: 1})
+			},
+			remaining: []string{"task2", "task3"},
+			attempts:  map[string]int{"task2": 2, "task3": 1},
+		},
+		{
+			name:    "retried concurrently",
+			pending: []Compensation{{GlobusTaskId: "task1", Attempts: 1}, {GlobusTaskId: "task2", Attempts: 1}},
+			failing: []string{"task1"},
+			during: func(q *CompensationQueue, globusClient globus.GlobusClient) {
+				q.Retry(context.Background(), globusClient)
+			},
+			remaining: []string{"task1"},
+			attempts:  map[string]int{"task1": 2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			queue, err := NewCompensationQueue(openTestStore(t, filepath.Join(t.TempDir(), "state.db")))
+			assert.Nil(t, err)
+			for _, c := range test.pending {
+				queue.Add(c)
+			}
+
+			started := false
+			var globusClient globus.GlobusClient
+			globusClient = fakeGlobusClient(func(taskId string) int {
+				if !started && test.during != nil {
+					started = true
+					test.during(queue, globusClient)
+				}
+				if slices.Contains(test.failing, taskId) {
+					return http.StatusInternalServerError
+				}
+				return http.StatusOK
+			})
+
+			attempted := queue.Retry(context.Background(), globusClient)
+			assert.Equal(t, len(test.pending), len(attempted))
+			for _, c := range attempted {
+				assert.Equal(t, slices.Contains(test.failing, c.GlobusTaskId), c.LastError != "", c.GlobusTaskId)
+			}
+
+			remaining := []string{}
+			for _, c := range queue.pending {
+				remaining = append(remaining, c.GlobusTaskId)
+				assert.Equal(t, test.attempts[c.GlobusTaskId], c.Attempts, c.GlobusTaskId)
+			}
+			assert.Equal(t, test.remaining, remaining)
+		})
+	}
+}
+
+func TestCompensationRetryInterrupted(t *testing.T) {
+	queue, err := NewCompensationQueue(openTestStore(t, filepath.Join(t.TempDir(), "state.db")))
+	assert.Nil(t, err)
+	queue.Add(Compensation{GlobusTaskId: "task1", Attempts: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempted := queue.Retry(ctx, fakeGlobusClient(func(string) int { return http.StatusOK }))
+	assert.Empty(t, attempted)
+	assert.Equal(t, 1, queue.Len())
+	assert.Equal(t, 1, queue.pending[0].Attempts)
+}
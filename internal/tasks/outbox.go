@@ -0,0 +1,338 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/logging"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/serviceuser"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/util"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	outboxMinBackoff = 1 * time.Second
+	outboxMaxBackoff = 5 * time.Minute
+)
+
+var outboxBackoff = util.Backoff{Initial: outboxMinBackoff, Max: outboxMaxBackoff}
+
+// A pending status patch for a SciCat job
+type JobUpdate struct {
+	Seq           uint64               `json:"seq"`
+	JobId         string               `json:"jobId"`
+	StatusCode    string               `json:"statusCode"`
+	StatusMessage string               `json:"statusMessage"`
+	Result        jobs.JobResultObject `json:"jobResultObject"`
+	// Request that caused the update, for the logs
+	RequestId string `json:"requestId,omitempty"`
+	// W3C traceparent of the span that enqueued the update, so sending it continues that trace
+	TraceParent string `json:"traceParent,omitempty"`
+	// Terminal updates are never coalesced away
+	Terminal    bool      `json:"terminal"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt"`
+}
+
+// Context of the logs and spans about the update
+func (u JobUpdate) context() context.Context {
+	ctx := logging.WithRequestId(context.Background(), u.RequestId)
+	return tracing.ContextWithTraceParent(ctx, u.TraceParent)
+}
+
+// Persists the content of the outbox between restarts
+type outboxStorage interface {
+	loadOutbox() ([]JobUpdate, error)
+	saveOutbox(updates []JobUpdate) error
+}
+
+// Durable, ordered queue of SciCat job patches.
+//
+// Updates for the same job are sent in the order they were enqueued. A failing
+// job is retried with exponential backoff without blocking updates of other jobs.
+// Consecutive non-terminal updates of a job are coalesced, so only the latest
+// progress is sent once SciCat becomes reachable again.
+type Outbox struct {
+	storage outboxStorage
+	send    func(JobUpdate) error
+	pending []JobUpdate
+	seq     uint64
+	mutex   sync.Mutex
+	wake    chan struct{}
+}
+
+func NewOutbox(storage outboxStorage, send func(JobUpdate) error) (*Outbox, error) {
+	pending, err := storage.loadOutbox()
+	if err != nil {
+		return nil, err
+	}
+	o := &Outbox{
+		storage: storage,
+		send:    send,
+		pending: pending,
+		wake:    make(chan struct{}, 1),
+	}
+	for _, u := range pending {
+		o.seq = max(o.seq, u.Seq)
+	}
+	if len(pending) > 0 {
+		slog.Info("Restored pending SciCat job updates", "count", len(pending))
+	}
+	return o, nil
+}
+
+// Create an outbox persisted in the state store
+func NewStoreOutbox(st *store.Store, send func(JobUpdate) error) (*Outbox, error) {
+	return NewOutbox(&storeOutboxStorage{store: st}, send)
+}
+
+// Queue a patch of the SciCat job. The call never blocks on SciCat. The request id and the
+// trace of ctx are kept with the update.
+func (o *Outbox) Enqueue(ctx context.Context, jobId string, statusCode string, statusMessage string, result jobs.JobResultObject, terminal bool) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.seq++
+	update := JobUpdate{
+		Seq:           o.seq,
+		JobId:         jobId,
+		StatusCode:    statusCode,
+		StatusMessage: statusMessage,
+		Result:        result,
+		RequestId:     logging.RequestId(ctx),
+		TraceParent:   tracing.TraceParent(ctx),
+		Terminal:      terminal,
+	}
+
+	// Replace the latest pending update of the same job if it is only progress.
+	// Keeping its position preserves the per-job ordering.
+	coalesced := false
+	for i := len(o.pending) - 1; i >= 0; i-- {
+		if o.pending[i].JobId != jobId {
+			continue
+		}
+		if !o.pending[i].Terminal {
+			update.Attempts = o.pending[i].Attempts
+			update.NextAttempt = o.pending[i].NextAttempt
+			o.pending[i] = update
+			coalesced = true
+		}
+		break
+	}
+	if !coalesced {
+		o.pending = append(o.pending, update)
+	}
+	o.persist(ctx)
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Drop all pending updates of a job, eg. when the job is deleted
+func (o *Outbox) Discard(ctx context.Context, jobId string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.pending = removeUpdates(o.pending, func(u JobUpdate) bool { return u.JobId == jobId })
+	o.persist(ctx)
+}
+
+// Whether updates of the job are waiting to be sent
+func (o *Outbox) HasPending(jobId string) bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	for _, u := range o.pending {
+		if u.JobId == jobId {
+			return true
+		}
+	}
+	return false
+}
+
+// Number of updates that were not yet accepted by SciCat
+func (o *Outbox) Len() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return len(o.pending)
+}
+
+// Send pending updates until the context is cancelled
+func (o *Outbox) Run(ctx context.Context) {
+	for {
+		wait := o.sendDue(ctx)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-o.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// Send pending updates until the outbox is empty or the context is done. Run must
+// not be running at the same time. Updates that are left stay persisted.
+func (o *Outbox) Flush(ctx context.Context) error {
+	for o.Len() > 0 {
+		wait := o.sendDue(ctx)
+		if o.Len() == 0 {
+			break
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%d SciCat job updates left in the outbox: %w", o.Len(), ctx.Err())
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// Send the first pending update of every job whose backoff has expired.
+// Returns the time to wait until the next update becomes due.
+func (o *Outbox) sendDue(ctx context.Context) time.Duration {
+	for _, update := range o.dueUpdates(time.Now()) {
+		if ctx.Err() != nil {
+			break
+		}
+		err := o.send(update)
+
+		switch {
+		case err == nil:
+			o.complete(update)
+		case errors.Is(err, scicat.ErrJobNotFound):
+			slog.ErrorContext(update.context(), "Dropping SciCat job update, the job does not exist", "jobId", update.JobId, "statusCode", update.StatusCode, "error", err)
+			o.complete(update)
+		case isRejectedUpdate(err):
+			// kept, so the update isn't lost if SciCat accepts it after being fixed
+			slog.ErrorContext(update.context(), "SciCat rejected job update", "jobId", update.JobId, "statusCode", update.StatusCode, "error", err)
+			o.retryLater(update, err)
+		default:
+			o.retryLater(update, err)
+		}
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	wait := outboxMaxBackoff
+	now := time.Now()
+	for _, u := range o.pending {
+		wait = min(wait, u.NextAttempt.Sub(now))
+	}
+	return max(wait, 0)
+}
+
+func (o *Outbox) dueUpdates(now time.Time) []JobUpdate {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	seen := map[string]bool{}
+	due := []JobUpdate{}
+	for _, u := range o.pending {
+		if seen[u.JobId] {
+			continue
+		}
+		seen[u.JobId] = true
+		if !u.NextAttempt.After(now) {
+			due = append(due, u)
+		}
+	}
+	return due
+}
+
+func (o *Outbox) complete(update JobUpdate) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	// the update may have been coalesced in the meantime, in which case the newer one is kept
+	o.pending = removeUpdates(o.pending, func(u JobUpdate) bool { return u.Seq == update.Seq })
+	o.persist(update.context())
+}
+
+func (o *Outbox) retryLater(update JobUpdate, err error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	for i := range o.pending {
+		if o.pending[i].Seq != update.Seq {
+			continue
+		}
+		o.pending[i].Attempts++
+		delay := outboxBackoff.Delay(o.pending[i].Attempts)
+		o.pending[i].NextAttempt = time.Now().Add(delay)
+		slog.WarnContext(update.context(), "SciCat job update failed, retrying later", "jobId", o.pending[i].JobId, "attempts", o.pending[i].Attempts, "retryIn", delay, "error", err)
+	}
+	o.persist(update.context())
+}
+
+// must be called with the mutex held
+func (o *Outbox) persist(ctx context.Context) {
+	if err := o.storage.saveOutbox(o.pending); err != nil {
+		slog.ErrorContext(ctx, "couldn't persist pending SciCat job updates", "error", err)
+	}
+}
+
+// Whether SciCat refused the update itself, as opposed to being unavailable
+func isRejectedUpdate(err error) bool {
+	var httpErr *scicat.HttpError
+	return errors.As(err, &httpErr) && httpErr.StatusCode < 500 && httpErr.StatusCode != http.StatusTooManyRequests
+}
+
+func removeUpdates(updates []JobUpdate, remove func(JobUpdate) bool) []JobUpdate {
+	kept := updates[:0]
+	for _, u := range updates {
+		if !remove(u) {
+			kept = append(kept, u)
+		}
+	}
+	return kept
+}
+
+// Send job updates to SciCat using the service user
+func ScicatJobUpdateSender(scicatClient *scicat.Client, serviceUser serviceuser.ScicatServiceUser) func(JobUpdate) error {
+	return func(u JobUpdate) (err error) {
+		ctx, span := tracing.Start(u.context(), "outbox.SendJobUpdate",
+			attribute.String("scicat.job_id", u.JobId),
+			attribute.Int("outbox.attempts", u.Attempts),
+		)
+		defer func() { tracing.End(span, err) }()
+
+		token, err := serviceUser.GetToken()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		_, err = scicatClient.UpdateJob(ctx, token, u.JobId, scicat.JobPatch{
+			StatusCode:      u.StatusCode,
+			StatusMessage:   u.StatusMessage,
+			JobResultObject: u.Result,
+		})
+		return err
+	}
+}
+
+const outboxScheduledName = "outbox"
+
+type storeOutboxStorage struct {
+	store *store.Store
+}
+
+func (s *storeOutboxStorage) loadOutbox() ([]JobUpdate, error) {
+	updates := []JobUpdate{}
+	_, err := s.store.LoadScheduled(outboxScheduledName, &updates)
+	return updates, err
+}
+
+func (s *storeOutboxStorage) saveOutbox(updates []JobUpdate) error {
+	return s.store.SaveScheduled(outboxScheduledName, updates)
+}
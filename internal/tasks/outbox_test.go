@@ -0,0 +1,131 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/logging"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestStore(t *testing.T, path string) *store.Store {
+	st, err := store.Open(path)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+func progress(bytes uint) jobs.JobResultObject {
+	return jobs.JobResultObject{BytesTransferred: bytes, Status: jobs.Transferring}
+}
+
+func TestOutboxCoalescing(t *testing.T) {
+	sent := []JobUpdate{}
+	outbox, err := NewStoreOutbox(openTestStore(t, filepath.Join(t.TempDir(), "state.db")), func(u JobUpdate) error {
+		sent = append(sent, u)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	outbox.Enqueue(context.Background(), "job1", "002", "transferring", progress(1), false)
+	outbox.Enqueue(context.Background(), "job1", "002", "transferring", progress(2), false)
+	outbox.Enqueue(context.Background(), "job2", "002", "transferring", progress(5), false)
+	outbox.Enqueue(context.Background(), "job1", "003", "finished", jobs.JobResultObject{Status: jobs.Finished}, true)
+	outbox.Enqueue(context.Background(), "job1", "997", "completed but can't mark dataset as archivable", jobs.JobResultObject{Status: jobs.Finished}, true)
+	assert.Equal(t, 3, outbox.Len())
+
+	outbox.sendDue(context.Background())
+	outbox.sendDue(context.Background())
+	assert.Equal(t, 0, outbox.Len())
+
+	assert.Equal(t, 3, len(sent))
+	assert.Equal(t, "003", sent[0].StatusCode)
+	assert.Equal(t, "job2", sent[1].JobId)
+	assert.Equal(t, "997", sent[2].StatusCode)
+}
+
+func TestOutboxRetryKeepsUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	failing := func(u JobUpdate) error { return errors.New("scicat unavailable") }
+
+	st, err := store.Open(path)
+	assert.Nil(t, err)
+	outbox, err := NewStoreOutbox(st, failing)
+	assert.Nil(t, err)
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := tracing.ContextWithTraceParent(logging.WithRequestId(context.Background(), "request1"), traceParent)
+	outbox.Enqueue(ctx, "job1", "003", "finished", jobs.JobResultObject{Status: jobs.Finished}, true)
+
+	wait := outbox.sendDue(context.Background())
+	assert.Equal(t, 1, outbox.Len())
+	assert.Greater(t, wait, time.Duration(0))
+
+	// pending updates survive a restart
+	assert.Nil(t, st.Close())
+	sent := []JobUpdate{}
+	restored, err := NewStoreOutbox(openTestStore(t, path), func(u JobUpdate) error {
+		sent = append(sent, u)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, restored.Len())
+	assert.Equal(t, 1, restored.pending[0].Attempts)
+
+	restored.pending[0].NextAttempt = time.Time{}
+	restored.sendDue(context.Background())
+	assert.Equal(t, 0, restored.Len())
+	assert.Equal(t, jobs.Finished, sent[0].Result.Status)
+	assert.Equal(t, "request1", sent[0].RequestId)
+	assert.Equal(t, traceParent, sent[0].TraceParent)
+}
+
+func TestOutboxDropsUnknownJobs(t *testing.T) {
+	outbox, err := NewStoreOutbox(openTestStore(t, filepath.Join(t.TempDir(), "state.db")), func(u JobUpdate) error {
+		return &scicat.HttpError{StatusCode: 400, DetailedError: scicat.DetailedError{Message: "cannot patch job", Err: scicat.ErrJobNotFound}}
+	})
+	assert.Nil(t, err)
+	outbox.Enqueue(context.Background(), "job1", "002", "transferring", progress(1), false)
+	outbox.sendDue(context.Background())
+	assert.Equal(t, 0, outbox.Len())
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, outboxMinBackoff, outboxBackoff.Delay(1))
+	assert.Equal(t, 2*outboxMinBackoff, outboxBackoff.Delay(2))
+	assert.Equal(t, outboxMaxBackoff, outboxBackoff.Delay(100))
+}
+
+func TestOutboxFlush(t *testing.T) {
+	attempts := 0
+	outbox, err := NewStoreOutbox(openTestStore(t, filepath.Join(t.TempDir(), "state.db")), func(u JobUpdate) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("scicat unavailable")
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	outbox.Enqueue(context.Background(), "job1", "003", "finished", jobs.JobResultObject{Status: jobs.Finished}, true)
+
+	// the retry after the first failure is within the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Nil(t, outbox.Flush(ctx))
+	assert.Equal(t, 0, outbox.Len())
+	assert.Equal(t, 2, attempts)
+
+	// updates that can't be delivered in time are kept
+	outbox.send = func(u JobUpdate) error { return errors.New("scicat unavailable") }
+	outbox.Enqueue(context.Background(), "job2", "003", "finished", jobs.JobResultObject{Status: jobs.Finished}, true)
+	expired, cancelExpired := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancelExpired()
+	assert.NotNil(t, outbox.Flush(expired))
+	assert.Equal(t, 1, outbox.Len())
+}
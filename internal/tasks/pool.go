@@ -1,23 +1,42 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/serviceuser"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
 	"github.com/alitto/pond/v2"
 )
 
 type TaskPool struct {
-	scicatUrl         string
+	scicat            *scicat.Client
 	globusClient      globus.GlobusClient
 	scicatServiceUser serviceuser.ScicatServiceUser
+	outbox            *Outbox
+	compensations     *CompensationQueue
+	store             *store.Store
 	pool              pond.Pool
-	taskPollInterval  time.Duration
+	intervals         *atomic.Pointer[taskIntervals]
 	cancelTask        map[string]chan struct{}
 	cancelMutex       *sync.Mutex
+	// closed when the pool stops, monitors then return without changing the transfer
+	stop        chan struct{}
+	stopOnce    *sync.Once
+	submissions *sync.WaitGroup
+}
+
+// Intervals used by new transfer monitors, they can change when the config is reloaded
+type taskIntervals struct {
+	poll      time.Duration
+	minUpdate time.Duration
 }
 
 type JobNotExistError struct {
@@ -28,57 +47,126 @@ func (e *JobNotExistError) Error() string {
 	return e.msg
 }
 
-func CreateTaskPool(scicatUrl string, globusClient globus.GlobusClient, scicatServiceUser serviceuser.ScicatServiceUser, maxConcurrency int, queueSize int, taskPollInterval uint) TaskPool {
+func CreateTaskPool(scicatClient *scicat.Client, globusClient globus.GlobusClient, scicatServiceUser serviceuser.ScicatServiceUser, outbox *Outbox, compensations *CompensationQueue, st *store.Store, maxConcurrency int, queueSize int, taskPollInterval uint, minUpdateInterval uint) TaskPool {
+	intervals := &atomic.Pointer[taskIntervals]{}
+	intervals.Store(&taskIntervals{
+		poll:      time.Duration(taskPollInterval) * time.Second,
+		minUpdate: time.Duration(minUpdateInterval) * time.Second,
+	})
 	return TaskPool{
-		scicatUrl:         scicatUrl,
+		scicat:            scicatClient,
 		globusClient:      globusClient,
 		scicatServiceUser: scicatServiceUser,
+		outbox:            outbox,
+		compensations:     compensations,
+		store:             st,
 		pool:              pond.NewPool(maxConcurrency, pond.WithQueueSize(queueSize)),
-		taskPollInterval:  time.Duration(taskPollInterval) * time.Second,
+		intervals:         intervals,
 		cancelTask:        map[string]chan struct{}{},
 		cancelMutex:       &sync.Mutex{},
+		stop:              make(chan struct{}),
+		stopOnce:          &sync.Once{},
+		submissions:       &sync.WaitGroup{},
 	}
 }
 
-func (tp TaskPool) AddTransferTask(globusTaskId string, datasetPid string, scicatJobId string, archivalJobInfo ArchivalJobInfo) pond.Task {
-	tp.cancelTask[scicatJobId] = make(chan struct{})
+// Start monitoring a transfer. The transfer is recorded in the state store, so it
+// can be resumed after a restart.
+func (tp TaskPool) AddTransferTask(transfer store.Transfer) pond.Task {
+	if transfer.Status == "" {
+		transfer.Status = jobs.Transferring
+	}
+	tp.putTransfer(transfer)
+
+	scicatJobId := transfer.ScicatJobId
+	cancel := make(chan struct{}, 1)
+	tp.cancelMutex.Lock()
+	tp.cancelTask[scicatJobId] = cancel
+	tp.cancelMutex.Unlock()
+
+	intervals := tp.intervals.Load()
 	task := transferTask{
-		scicatUrl:         &tp.scicatUrl,
+		scicatUrl:         tp.scicat.Url(),
+		scicatHttpClient:  tp.scicat.HttpClient(),
 		globusClient:      tp.globusClient,
 		scicatServiceUser: tp.scicatServiceUser,
-		globusTaskId:      globusTaskId,
-		datasetPid:        datasetPid,
+		outbox:            tp.outbox,
+		store:             tp.store,
+		globusTaskId:      transfer.GlobusTaskId,
+		datasetPids:       transfer.DatasetPids,
 		scicatJobId:       scicatJobId,
-		taskPollInterval:  tp.taskPollInterval,
-		cancel:            tp.cancelTask[scicatJobId],
-		archivalJobInfo:   archivalJobInfo,
+		taskPollInterval:  intervals.poll,
+		minUpdateInterval: intervals.minUpdate,
+		cancel:            cancel,
+		stop:              tp.stop,
+		archivalJobInfo:   transfer.ArchivalJobInfo,
+		ctx:               transferContext(transfer),
 		cleanup: func() {
-			tp.cancelMutex.Lock()
-			defer tp.cancelMutex.Unlock()
-			delete(tp.cancelTask, scicatJobId)
+			tp.removeMonitor(scicatJobId)
 		},
 	}
 
 	return tp.pool.Submit(task.execute)
 }
 
+// Whether a transfer for the given SciCat job is currently monitored
+func (tp TaskPool) IsMonitored(scicatJobId string) bool {
+	tp.cancelMutex.Lock()
+	defer tp.cancelMutex.Unlock()
+	_, ok := tp.cancelTask[scicatJobId]
+	return ok
+}
+
+// Called by a monitor when it returns
+func (tp TaskPool) removeMonitor(scicatJobId string) {
+	tp.cancelMutex.Lock()
+	defer tp.cancelMutex.Unlock()
+	delete(tp.cancelTask, scicatJobId)
+}
+
 func (tp TaskPool) CancelTransferTask(scicatJobId string) error {
 	tp.cancelMutex.Lock()
 	defer tp.cancelMutex.Unlock()
 	if cancelChannel, ok := tp.cancelTask[scicatJobId]; ok {
-		cancelChannel <- struct{}{}
+		// a pending cancel is enough, the monitor may be finishing and never read it
+		select {
+		case cancelChannel <- struct{}{}:
+		default:
+		}
 		return nil
 	}
 	return &JobNotExistError{fmt.Sprintf("job with ID '%s' does not exist or is already cancelled/removed", scicatJobId)}
 }
 
-func (tp TaskPool) DeleteTransferTask(scicatJobId string) error {
+func (tp TaskPool) DeleteTransferTask(ctx context.Context, scicatJobId string) error {
 	_ = tp.CancelTransferTask(scicatJobId)
+	tp.outbox.Discard(ctx, scicatJobId)
+	if err := tp.store.DeleteTransfer(scicatJobId); err != nil {
+		slog.ErrorContext(ctx, "couldn't remove transfer from state store", "scicatJobId", scicatJobId, "error", err)
+	}
 	token, err := tp.scicatServiceUser.GetToken()
 	if err != nil {
 		return err
 	}
-	return DeleteScicatJob(tp.scicatUrl, token, scicatJobId)
+	return tp.scicat.DeleteJob(ctx, token, scicatJobId)
+}
+
+// Apply new task settings. Running monitors keep their intervals, the new ones
+// are used by monitors started afterwards.
+func (tp TaskPool) UpdateSettings(maxConcurrency int, taskPollInterval uint, minUpdateInterval uint) {
+	tp.intervals.Store(&taskIntervals{
+		poll:      time.Duration(taskPollInterval) * time.Second,
+		minUpdate: time.Duration(minUpdateInterval) * time.Second,
+	})
+	if maxConcurrency > 0 && maxConcurrency != tp.pool.MaxConcurrency() {
+		slog.Info("Resizing task pool", "maxConcurrency", maxConcurrency)
+		tp.pool.Resize(maxConcurrency)
+	}
+}
+
+// The queue size is fixed when the pool is created
+func (tp TaskPool) QueueSize() int {
+	return tp.pool.QueueSize()
 }
 
 func (tp TaskPool) CanSubmitJob() bool {
@@ -91,3 +179,30 @@ func (tp TaskPool) CanSubmitJob() bool {
 func (tp TaskPool) IsQueueSizeLimited() bool {
 	return tp.pool.QueueSize() > 0
 }
+
+// Wait until submissions running in the background are done, or the context expires
+func (tp TaskPool) WaitForSubmissions(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		tp.submissions.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("submissions still running: %w", ctx.Err())
+	}
+}
+
+// Stop monitoring all transfers, without cancelling them. The transfers stay
+// unfinished in the state store, so they are resumed by the next instance.
+func (tp TaskPool) Stop(ctx context.Context) error {
+	tp.stopOnce.Do(func() { close(tp.stop) })
+	select {
+	case <-tp.pool.Stop().Done():
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("transfer monitors still running: %w", ctx.Err())
+	}
+}
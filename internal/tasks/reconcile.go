@@ -0,0 +1,341 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/globusclient"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/serviceuser"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+)
+
+const (
+	// Globus tasks requested per page
+	reconcileTaskPageSize = 100
+	// Only the most recent globus tasks are checked for orphans
+	reconcileMaxTasks = 1000
+)
+
+type OrphanedTask struct {
+	GlobusTaskId string                 `json:"globusTaskId"`
+	Label        string                 `json:"label"`
+	Status       string                 `json:"status"`
+	Action       config.ReconcileAction `json:"action"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+type OrphanedJob struct {
+	ScicatJobId string                 `json:"scicatJobId"`
+	Reason      string                 `json:"reason"`
+	Action      config.ReconcileAction `json:"action"`
+}
+
+// Outcome of one reconciliation run
+type ReconcileReport struct {
+	Time time.Time `json:"time"`
+	// Transfers that were not monitored and got a new monitor
+	Reattached []string `json:"reattached"`
+	// Jobs still transferring in SciCat although the transfer is done
+	Resynced      []string       `json:"resynced"`
+	OrphanedTasks []OrphanedTask `json:"orphanedTasks"`
+	OrphanedJobs  []OrphanedJob  `json:"orphanedJobs"`
+	// Submissions that were interrupted before a globus task was attached
+	Interrupted []string `json:"interrupted"`
+	// Retried cancellations of globus tasks of failed submissions
+	Compensations []Compensation `json:"compensations"`
+	// Set if the run was aborted
+	Error string `json:"error,omitempty"`
+}
+
+// Periodically compares SciCat jobs, globus tasks and the state store, and fixes drift between them
+type Reconciler struct {
+	scicat       *scicat.Client
+	serviceUser  serviceuser.ScicatServiceUser
+	globusClient globus.GlobusClient
+	pool         TaskPool
+	conf         config.ReconcileConfig
+	lastReport   atomic.Pointer[ReconcileReport]
+}
+
+func NewReconciler(scicatClient *scicat.Client, serviceUser serviceuser.ScicatServiceUser, globusClient globus.GlobusClient, pool TaskPool, conf config.ReconcileConfig) *Reconciler {
+	return &Reconciler{
+		scicat:       scicatClient,
+		serviceUser:  serviceUser,
+		globusClient: globusClient,
+		pool:         pool,
+		conf:         conf,
+	}
+}
+
+// Reconcile at the configured interval until the context is cancelled. Cancelling interrupts
+// a running reconciliation, Run returns once it stopped.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(r.conf.Interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reconcile(ctx)
+		}
+	}
+}
+
+// The report of the most recent run, or nil if there was none yet
+func (r *Reconciler) LastReport() *ReconcileReport {
+	return r.lastReport.Load()
+}
+
+// Run one reconciliation. Once ctx is cancelled, no further fixes are applied.
+func (r *Reconciler) Reconcile(ctx context.Context) ReconcileReport {
+	ctx, span := tracing.Start(ctx, "tasks.Reconcile")
+	report := ReconcileReport{
+		Time:          time.Now(),
+		Reattached:    []string{},
+		Resynced:      []string{},
+		OrphanedTasks: []OrphanedTask{},
+		OrphanedJobs:  []OrphanedJob{},
+		Interrupted:   []string{},
+		Compensations: []Compensation{},
+	}
+	defer func() {
+		r.lastReport.Store(&report)
+		if report.Error != "" {
+			tracing.End(span, errors.New(report.Error))
+		} else {
+			tracing.End(span, nil)
+		}
+	}()
+
+	report.Compensations = r.pool.compensations.Retry(ctx, r.globusClient)
+
+	unfinishedJobs, err := listUnfinishedJobs(ctx, r.scicat, r.serviceUser)
+	if err != nil {
+		report.Error = err.Error()
+		slog.ErrorContext(ctx, "reconciliation failed, can't list unfinished jobs", "error", err)
+		return report
+	}
+	globusTasks, err := r.listRecentGlobusTasks(ctx)
+	if err != nil {
+		report.Error = err.Error()
+		slog.ErrorContext(ctx, "reconciliation failed, can't list globus tasks", "error", err)
+		return report
+	}
+	transfers, err := r.pool.store.ListTransfers(nil)
+	if err != nil {
+		report.Error = err.Error()
+		slog.ErrorContext(ctx, "reconciliation failed, can't read state store", "error", err)
+		return report
+	}
+
+	plan := planReconciliation(unfinishedJobs, globusTasks, transfers, r.pool.IsMonitored, r.pool.outbox.HasPending, report.Time, time.Duration(r.conf.GracePeriod)*time.Second)
+	r.apply(ctx, plan, &report)
+
+	slog.InfoContext(ctx, "Reconciled transfers",
+		"reattached", len(report.Reattached),
+		"resynced", len(report.Resynced),
+		"orphanedTasks", len(report.OrphanedTasks),
+		"orphanedJobs", len(report.OrphanedJobs),
+		"interrupted", len(report.Interrupted),
+		"compensations", len(report.Compensations),
+	)
+	return report
+}
+
+func (r *Reconciler) listRecentGlobusTasks(ctx context.Context) ([]globus.Task, error) {
+	globusTasks := []globus.Task{}
+	for offset := uint(0); offset < reconcileMaxTasks; offset += reconcileTaskPageSize {
+		page, err := globusclient.ListTasks(ctx, r.globusClient, offset, reconcileTaskPageSize)
+		if err != nil {
+			return nil, err
+		}
+		globusTasks = append(globusTasks, page.Data...)
+		if len(page.Data) < reconcileTaskPageSize || int(offset)+len(page.Data) >= page.Total {
+			break
+		}
+	}
+	return globusTasks, nil
+}
+
+type reconcilePlan struct {
+	reattach      []store.Transfer
+	resync        []store.Transfer
+	orphanedTasks []globus.Task
+	orphanedJobs  []OrphanedJob
+	interrupted   []store.Transfer
+}
+
+// Decide what needs fixing. Recently created jobs and tasks are ignored, as they
+// may belong to a submission that is still in progress.
+func planReconciliation(
+	unfinishedJobs []jobs.ScicatJob,
+	globusTasks []globus.Task,
+	transfers []store.Transfer,
+	isMonitored func(string) bool,
+	hasPendingUpdates func(string) bool,
+	now time.Time,
+	gracePeriod time.Duration,
+) reconcilePlan {
+	plan := reconcilePlan{}
+
+	knownTasks := map[string]bool{}
+	storedTransfers := map[string]store.Transfer{}
+	for _, transfer := range transfers {
+		storedTransfers[transfer.ScicatJobId] = transfer
+		knownTasks[transfer.GlobusTaskId] = true
+		if transfer.IsDone() || isMonitored(transfer.ScicatJobId) {
+			continue
+		}
+		if transfer.Status == jobs.Submitting && now.Sub(transfer.UpdatedAt) < gracePeriod {
+			continue // the submission may still be in progress
+		}
+		if transfer.GlobusTaskId != "" {
+			// the monitor also moves interrupted submissions to transferring
+			plan.reattach = append(plan.reattach, transfer)
+		} else if transfer.Status == jobs.Submitting {
+			plan.interrupted = append(plan.interrupted, transfer)
+		}
+	}
+
+	for _, job := range unfinishedJobs {
+		knownTasks[job.JobResultObject.GlobusTaskId] = true
+		if transfer, ok := storedTransfers[job.ID]; ok {
+			// the final state got lost on the way to SciCat
+			if transfer.IsDone() && !hasPendingUpdates(job.ID) {
+				plan.resync = append(plan.resync, transfer)
+			}
+			continue
+		}
+		if isMonitored(job.ID) || now.Sub(job.CreatedAt) < gracePeriod {
+			continue
+		}
+		transfer, reason := transferFromJob(job)
+		if reason != "" {
+			plan.orphanedJobs = append(plan.orphanedJobs, OrphanedJob{ScicatJobId: job.ID, Reason: reason})
+			continue
+		}
+		plan.reattach = append(plan.reattach, transfer)
+	}
+
+	for _, task := range globusTasks {
+		if task.Type != "TRANSFER" || knownTasks[task.TaskId] {
+			continue
+		}
+		if task.Status != "ACTIVE" && task.Status != "INACTIVE" {
+			continue
+		}
+		requested, err := time.Parse(time.RFC3339, task.RequestTime)
+		if err == nil && now.Sub(requested) < gracePeriod {
+			continue
+		}
+		plan.orphanedTasks = append(plan.orphanedTasks, task)
+	}
+
+	return plan
+}
+
+func (r *Reconciler) apply(ctx context.Context, plan reconcilePlan, report *ReconcileReport) {
+	// A new monitor polls globus right away, which fixes the status of the job
+	for _, transfer := range plan.reattach {
+		if ctx.Err() != nil {
+			return
+		}
+		// a restore or submission may have started monitoring the transfer since the plan was made
+		if r.pool.IsMonitored(transfer.ScicatJobId) {
+			continue
+		}
+		if stored, err := r.pool.store.GetTransfer(transfer.ScicatJobId); err == nil && stored.IsDone() {
+			continue
+		}
+		slog.WarnContext(transferContext(transfer), "Reattaching monitor to unmonitored transfer", "jobId", transfer.ScicatJobId, "globusTaskId", transfer.GlobusTaskId)
+		r.pool.AddTransferTask(transfer)
+		report.Reattached = append(report.Reattached, transfer.ScicatJobId)
+	}
+
+	for _, planned := range plan.resync {
+		if ctx.Err() != nil {
+			return
+		}
+		// resend what the store knows now, unless the transfer is monitored or updated again
+		transfer, err := r.pool.store.GetTransfer(planned.ScicatJobId)
+		if err != nil || !transfer.IsDone() || r.pool.IsMonitored(transfer.ScicatJobId) || r.pool.outbox.HasPending(transfer.ScicatJobId) {
+			continue
+		}
+		statusCode, statusMessage := finalStatus(transfer.Status)
+		transferCtx := transferContext(transfer)
+		slog.WarnContext(transferCtx, "Resending final status of transfer to SciCat", "jobId", transfer.ScicatJobId, "status", transfer.Status)
+		r.pool.outbox.Enqueue(transferCtx, transfer.ScicatJobId, statusCode, statusMessage, jobs.JobResultObject{
+			GlobusTaskId:     transfer.GlobusTaskId,
+			BytesTransferred: transfer.Progress.BytesTransferred,
+			FilesTransferred: transfer.Progress.FilesTransferred,
+			FilesTotal:       transfer.Progress.FilesTotal,
+			Status:           transfer.Status,
+		}, true)
+		report.Resynced = append(report.Resynced, transfer.ScicatJobId)
+	}
+
+	for _, transfer := range plan.interrupted {
+		if ctx.Err() != nil {
+			return
+		}
+		_ = r.pool.failSubmission(transferContext(transfer), transfer, StepSubmitGlobus, errors.New("the submission was interrupted before the globus task was attached"))
+		report.Interrupted = append(report.Interrupted, transfer.ScicatJobId)
+	}
+
+	for _, task := range plan.orphanedTasks {
+		if ctx.Err() != nil {
+			return
+		}
+		orphan := OrphanedTask{
+			GlobusTaskId: task.TaskId,
+			Label:        task.Label,
+			Status:       task.Status,
+			Action:       r.conf.OrphanedTasks,
+		}
+		if r.conf.OrphanedTasks == config.ActionCancel {
+			if _, err := globusclient.CancelTask(ctx, r.globusClient, task.TaskId); err != nil {
+				orphan.Error = err.Error()
+			}
+		}
+		slog.WarnContext(ctx, "Found globus task without SciCat job", "globusTaskId", task.TaskId, "label", task.Label, "action", orphan.Action, "error", orphan.Error)
+		report.OrphanedTasks = append(report.OrphanedTasks, orphan)
+	}
+
+	for _, orphan := range plan.orphanedJobs {
+		if ctx.Err() != nil {
+			return
+		}
+		orphan.Action = r.conf.OrphanedJobs
+		if r.conf.OrphanedJobs == config.ActionFail {
+			r.pool.outbox.Enqueue(ctx, orphan.ScicatJobId, "995", "the job has no transfer associated", jobs.JobResultObject{
+				Status: jobs.Failed,
+				Error:  orphan.Reason,
+			}, true)
+		}
+		slog.WarnContext(ctx, "Found SciCat job without globus task", "jobId", orphan.ScicatJobId, "reason", orphan.Reason, "action", orphan.Action)
+		report.OrphanedJobs = append(report.OrphanedJobs, orphan)
+	}
+}
+
+// SciCat status code and message for the final state of a transfer
+func finalStatus(status jobs.JobStatus) (string, string) {
+	switch status {
+	case jobs.Finished:
+		return "003", "finished"
+	case jobs.Cancelled:
+		return "003", "cancelled"
+	case jobs.SubmissionFailed:
+		return "994", "submission failed"
+	default:
+		return "998", "transfer failed"
+	}
+}
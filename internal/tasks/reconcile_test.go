@@ -0,0 +1,105 @@
+package tasks
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanReconciliation(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-time.Hour)
+	recent := now.Add(-time.Minute)
+
+	transferJob := func(id string, taskId string, createdAt time.Time) jobs.ScicatJob {
+		return jobs.ScicatJob{
+			ID:        id,
+			CreatedAt: createdAt,
+			JobParams: jobs.JobParams{DatasetList: []jobs.Dataset{{Pid: "pid"}}},
+			JobResultObject: jobs.JobResultObject{
+				GlobusTaskId: taskId,
+				Status:       jobs.Transferring,
+			},
+		}
+	}
+	unfinishedJobs := []jobs.ScicatJob{
+		transferJob("monitored", "task-monitored", old),
+		transferJob("unmonitored", "task-unmonitored", old),
+		transferJob("finished", "task-finished", old),
+		transferJob("no-task", "", old),
+		transferJob("submitting", "", recent),
+		transferJob("pending-update", "task-pending", old),
+	}
+	transfers := []store.Transfer{
+		{ScicatJobId: "finished", GlobusTaskId: "task-finished", Status: jobs.Finished},
+		{ScicatJobId: "pending-update", GlobusTaskId: "task-pending", Status: jobs.Failed},
+		{ScicatJobId: "stored", GlobusTaskId: "task-stored", Status: jobs.Transferring},
+		{ScicatJobId: "interrupted", Status: jobs.Submitting, UpdatedAt: old},
+		{ScicatJobId: "interrupted-submitted", GlobusTaskId: "task-interrupted", Status: jobs.Submitting, UpdatedAt: old},
+		{ScicatJobId: "in-progress", Status: jobs.Submitting, UpdatedAt: recent},
+		{ScicatJobId: "submission-failed", GlobusTaskId: "task-failed", Status: jobs.SubmissionFailed, UpdatedAt: old},
+	}
+	globusTasks := []globus.Task{
+		{TaskId: "task-monitored", Type: "TRANSFER", Status: "ACTIVE"},
+		{TaskId: "orphan", Type: "TRANSFER", Status: "ACTIVE", RequestTime: old.Format(time.RFC3339)},
+		{TaskId: "new", Type: "TRANSFER", Status: "ACTIVE", RequestTime: recent.Format(time.RFC3339)},
+		{TaskId: "done", Type: "TRANSFER", Status: "SUCCEEDED", RequestTime: old.Format(time.RFC3339)},
+		{TaskId: "delete", Type: "DELETE", Status: "ACTIVE", RequestTime: old.Format(time.RFC3339)},
+		{TaskId: "task-failed", Type: "TRANSFER", Status: "ACTIVE", RequestTime: old.Format(time.RFC3339)},
+	}
+
+	plan := planReconciliation(
+		unfinishedJobs,
+		globusTasks,
+		transfers,
+		func(jobId string) bool { return jobId == "monitored" },
+		func(jobId string) bool { return jobId == "pending-update" },
+		now,
+		10*time.Minute,
+	)
+
+	reattached := []string{}
+	for _, transfer := range plan.reattach {
+		reattached = append(reattached, transfer.ScicatJobId)
+	}
+	assert.ElementsMatch(t, []string{"stored", "unmonitored", "interrupted-submitted"}, reattached)
+
+	assert.Equal(t, 1, len(plan.interrupted))
+	assert.Equal(t, "interrupted", plan.interrupted[0].ScicatJobId)
+
+	assert.Equal(t, 1, len(plan.resync))
+	assert.Equal(t, "finished", plan.resync[0].ScicatJobId)
+
+	assert.Equal(t, 1, len(plan.orphanedJobs))
+	assert.Equal(t, "no-task", plan.orphanedJobs[0].ScicatJobId)
+
+	assert.Equal(t, 1, len(plan.orphanedTasks))
+	assert.Equal(t, "orphan", plan.orphanedTasks[0].TaskId)
+}
+
+func TestFinalStatus(t *testing.T) {
+	code, _ := finalStatus(jobs.Finished)
+	assert.Equal(t, "003", code)
+	code, _ = finalStatus(jobs.Failed)
+	assert.Equal(t, "998", code)
+}
+
+func TestCompensationQueuePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	st, err := store.Open(path)
+	assert.Nil(t, err)
+	queue, err := NewCompensationQueue(st)
+	assert.Nil(t, err)
+	queue.Add(Compensation{GlobusTaskId: "task1", ScicatJobId: "job1", Attempts: 1})
+	assert.Nil(t, st.Close())
+
+	restored, err := NewCompensationQueue(openTestStore(t, path))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, restored.Len())
+	assert.Equal(t, "task1", restored.pending[0].GlobusTaskId)
+}
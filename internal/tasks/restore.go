@@ -0,0 +1,210 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/serviceuser"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+)
+
+// Number of SciCat jobs requested per page when restoring
+const restorePageSize = 100
+
+const (
+	RestoreSourceStore  = "store"
+	RestoreSourceScicat = "scicat"
+)
+
+type RestoredTransfer struct {
+	ScicatJobId  string `json:"scicatJobId"`
+	GlobusTaskId string `json:"globusTaskId"`
+	Source       string `json:"source"`
+}
+
+type SkippedTransfer struct {
+	ScicatJobId string `json:"scicatJobId"`
+	Reason      string `json:"reason"`
+}
+
+// Outcome of resuming unfinished transfers at startup
+type RestoreSummary struct {
+	Time    time.Time          `json:"time"`
+	Resumed []RestoredTransfer `json:"resumed"`
+	Skipped []SkippedTransfer  `json:"skipped"`
+	// Set if restoring was aborted
+	Error string `json:"error,omitempty"`
+}
+
+func (s *RestoreSummary) skip(ctx context.Context, scicatJobId string, reason string) {
+	slog.WarnContext(ctx, "transfer cannot be resumed", "jobId", scicatJobId, "reason", reason)
+	s.Skipped = append(s.Skipped, SkippedTransfer{ScicatJobId: scicatJobId, Reason: reason})
+}
+
+func (s *RestoreSummary) resume(pool TaskPool, transfer store.Transfer, source string) {
+	pool.AddTransferTask(transfer)
+	s.Resumed = append(s.Resumed, RestoredTransfer{
+		ScicatJobId:  transfer.ScicatJobId,
+		GlobusTaskId: transfer.GlobusTaskId,
+		Source:       source,
+	})
+}
+
+// Resume monitoring unfinished transfers. Transfers recorded in the state store
+// are resumed first, then unfinished SciCat jobs unknown to the store.
+func RestoreTransfers(ctx context.Context, scicatClient *scicat.Client, serviceUser serviceuser.ScicatServiceUser, pool TaskPool) (RestoreSummary, error) {
+	ctx, span := tracing.Start(ctx, "tasks.RestoreTransfers")
+	summary := RestoreSummary{
+		Time:    time.Now(),
+		Resumed: []RestoredTransfer{},
+		Skipped: []SkippedTransfer{},
+	}
+
+	err := restoreTransfersFromStore(&summary, pool)
+	if err == nil {
+		err = restoreGlobusTransferJobsFromScicat(ctx, &summary, scicatClient, serviceUser, pool)
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+
+	slog.InfoContext(ctx, "Restored unfinished transfers", "resumed", len(summary.Resumed), "skipped", len(summary.Skipped))
+	tracing.End(span, err)
+	return summary, err
+}
+
+func restoreTransfersFromStore(summary *RestoreSummary, pool TaskPool) error {
+	unfinished, err := pool.store.ListTransfers(func(t store.Transfer) bool { return !t.IsDone() })
+	if err != nil {
+		return err
+	}
+	for _, transfer := range unfinished {
+		if pool.IsMonitored(transfer.ScicatJobId) {
+			continue
+		}
+		if transfer.GlobusTaskId == "" {
+			summary.skip(transferContext(transfer), transfer.ScicatJobId, "transfer has no globus task id")
+			continue
+		}
+		summary.resume(pool, transfer, RestoreSourceStore)
+	}
+	return nil
+}
+
+func restoreGlobusTransferJobsFromScicat(ctx context.Context, summary *RestoreSummary, scicatClient *scicat.Client, serviceUser serviceuser.ScicatServiceUser, pool TaskPool) error {
+	unfinishedJobs, err := listUnfinishedJobs(ctx, scicatClient, serviceUser)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range unfinishedJobs {
+		if pool.IsMonitored(job.ID) {
+			continue
+		}
+		if _, err := pool.store.GetTransfer(job.ID); err == nil {
+			continue // the state store is authoritative for known transfers
+		}
+		transfer, reason := transferFromJob(job)
+		if reason != "" {
+			summary.skip(ctx, job.ID, reason)
+			continue
+		}
+		summary.resume(pool, transfer, RestoreSourceScicat)
+	}
+	return nil
+}
+
+// Fetch all transfer jobs that are still transferring, page by page. Pages continue after the
+// id of the last job, as jobs finishing meanwhile would shift offsets and skip other jobs.
+func listUnfinishedJobs(ctx context.Context, scicatClient *scicat.Client, serviceUser serviceuser.ScicatServiceUser) ([]jobs.ScicatJob, error) {
+	unfinishedJobs := []jobs.ScicatJob{}
+	afterId := ""
+	for {
+		token, err := serviceUser.GetToken()
+		if err != nil {
+			return nil, err
+		}
+		filter, err := unfinishedJobsFilter(afterId, restorePageSize)
+		if err != nil {
+			return nil, err
+		}
+		pageJobs, err := scicatClient.GetJobList(ctx, token, filter)
+		if err != nil {
+			return nil, err
+		}
+		unfinishedJobs = append(unfinishedJobs, pageJobs...)
+		if len(pageJobs) < restorePageSize {
+			return unfinishedJobs, nil
+		}
+		afterId = pageJobs[len(pageJobs)-1].ID
+	}
+}
+
+// Filter for the page of transfer jobs that are still transferring following the job afterId,
+// or the first page if it's empty
+func unfinishedJobsFilter(afterId string, limit int) (string, error) {
+	where := map[string]any{
+		"type":                   "globus_transfer_job",
+		"jobResultObject.status": jobs.Transferring,
+	}
+	if afterId != "" {
+		where["_id"] = map[string]any{"$gt": afterId}
+	}
+	filter, err := json.Marshal(map[string]any{
+		"where": where,
+		"limits": map[string]any{
+			"limit": limit,
+			"order": "_id:asc",
+		},
+	})
+	return string(filter), err
+}
+
+// Reconstruct a transfer from its SciCat job. Returns a reason if the job can't be resumed.
+func transferFromJob(job jobs.ScicatJob) (store.Transfer, string) {
+	if job.JobResultObject.GlobusTaskId == "" {
+		return store.Transfer{}, "job has no globus task id"
+	}
+	if len(job.JobParams.DatasetList) == 0 {
+		return store.Transfer{}, "job has no datasets associated"
+	}
+
+	pids := make([]string, len(job.JobParams.DatasetList))
+	for i, dataset := range job.JobParams.DatasetList {
+		pids[i] = dataset.Pid
+	}
+
+	transfer := store.Transfer{
+		ScicatJobId:  job.ID,
+		GlobusTaskId: job.JobResultObject.GlobusTaskId,
+		DatasetPids:  pids,
+		Status:       jobs.Transferring,
+		ProxyVersion: job.JobParams.ProxyVersion,
+		RequestId:    job.JobParams.RequestId,
+		CreatedAt:    job.CreatedAt,
+	}
+	if job.JobParams.ArchivalJobInfo != nil {
+		transfer.ArchivalJobInfo = *job.JobParams.ArchivalJobInfo
+	} else {
+		// jobs created by older versions only carry the owner, and always archived
+		transfer.ArchivalJobInfo = jobs.ArchivalJobInfo{
+			OwnerUser:    job.OwnerUser,
+			OwnerGroup:   job.OwnerGroup,
+			AutoArchive:  true,
+			ContactEmail: job.ContactEmail,
+		}
+	}
+	if job.JobParams.TransferParams != nil {
+		transfer.TransferParams = *job.JobParams.TransferParams
+	}
+	// the files are the same for all datasets, symlinks aren't recorded on the job
+	for _, file := range job.JobParams.DatasetList[0].Files {
+		transfer.FileList = append(transfer.FileList, jobs.TransferFile{Path: file})
+	}
+	return transfer, ""
+}
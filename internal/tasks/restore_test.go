@@ -0,0 +1,72 @@
+package tasks
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferFromJob(t *testing.T) {
+	job := jobs.ScicatJob{
+		ID:         "job1",
+		OwnerUser:  "user",
+		OwnerGroup: "group",
+		JobParams: jobs.JobParams{
+			DatasetList: []jobs.Dataset{{Pid: "pid1", Files: []string{"a.txt"}}, {Pid: "pid2", Files: []string{"a.txt"}}},
+			ArchivalJobInfo: &jobs.ArchivalJobInfo{
+				OwnerUser:   "user",
+				OwnerGroup:  "group",
+				AutoArchive: false,
+			},
+			TransferParams: &jobs.TransferParams{
+				SourceFacility: "SRC",
+			},
+			ProxyVersion: "v1.2.3",
+		},
+		JobResultObject: jobs.JobResultObject{GlobusTaskId: "task1", Status: jobs.Transferring},
+	}
+
+	transfer, reason := transferFromJob(job)
+	assert.Equal(t, "", reason)
+	assert.Equal(t, []string{"pid1", "pid2"}, transfer.DatasetPids)
+	assert.False(t, transfer.AutoArchive)
+	assert.Equal(t, "SRC", transfer.SourceFacility)
+	assert.Equal(t, []jobs.TransferFile{{Path: "a.txt"}}, transfer.FileList)
+	assert.Equal(t, "v1.2.3", transfer.ProxyVersion)
+
+	// legacy jobs are archived
+	job.JobParams.ArchivalJobInfo = nil
+	transfer, reason = transferFromJob(job)
+	assert.Equal(t, "", reason)
+	assert.True(t, transfer.AutoArchive)
+	assert.Equal(t, "group", transfer.OwnerGroup)
+
+	job.JobParams.DatasetList = nil
+	_, reason = transferFromJob(job)
+	assert.NotEqual(t, "", reason)
+
+	job.JobResultObject.GlobusTaskId = ""
+	_, reason = transferFromJob(job)
+	assert.NotEqual(t, "", reason)
+}
+
+func TestUnfinishedJobsFilter(t *testing.T) {
+	filter, err := unfinishedJobsFilter("", 100)
+	assert.Nil(t, err)
+
+	var parsed map[string]map[string]any
+	assert.Nil(t, json.Unmarshal([]byte(filter), &parsed))
+	assert.Equal(t, "globus_transfer_job", parsed["where"]["type"])
+	assert.NotContains(t, parsed["where"], "_id")
+	assert.NotContains(t, parsed["limits"], "skip")
+	assert.EqualValues(t, 100, parsed["limits"]["limit"])
+	assert.Equal(t, "_id:asc", parsed["limits"]["order"])
+
+	// following pages continue after the last job
+	filter, err = unfinishedJobsFilter("job100", 100)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal([]byte(filter), &parsed))
+	assert.Equal(t, map[string]any{"$gt": "job100"}, parsed["where"]["_id"])
+}
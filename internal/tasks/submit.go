@@ -0,0 +1,145 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/globusclient"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Steps of submitting a transfer. A failed step is recorded on the SciCat job.
+const (
+	StepCreateJob    = "create_job"
+	StepSubmitGlobus = "submit_globus"
+	StepAttachTask   = "attach_task"
+)
+
+// Error of a failed submission step
+type SubmissionError struct {
+	Step string
+	// Empty if the job couldn't be created
+	ScicatJobId string
+	Err         error
+}
+
+func (e *SubmissionError) Error() string {
+	return fmt.Sprintf("submission step '%s' failed: %s", e.Step, e.Err.Error())
+}
+
+func (e *SubmissionError) Unwrap() error {
+	return e.Err
+}
+
+// Submit a transfer and start monitoring it.
+//
+// The SciCat job is created first in the submitting state, then the transfer
+// is submitted to globus using the submit function, which returns the globus
+// task id, and finally the task is attached to the job. If the task can't be
+// attached, it is cancelled again; failing cancellations are left to the reconciler.
+func (tp TaskPool) SubmitTransfer(ctx context.Context, transfer store.Transfer, submit func(context.Context, store.Transfer) (string, error)) (store.Transfer, error) {
+	transfer, err := tp.CreateTransferJob(ctx, transfer)
+	if err != nil {
+		return transfer, err
+	}
+	return tp.CompleteSubmission(ctx, transfer, submit)
+}
+
+// First step of the submission: create the SciCat job in the submitting state
+func (tp TaskPool) CreateTransferJob(ctx context.Context, transfer store.Transfer) (_ store.Transfer, err error) {
+	ctx, span := tracing.Start(ctx, "tasks.CreateTransferJob")
+	defer func() { tracing.End(span, err) }()
+
+	token, err := tp.scicatServiceUser.GetToken()
+	if err != nil {
+		return transfer, &SubmissionError{Step: StepCreateJob, Err: fmt.Errorf("service user login failed: %w", err)}
+	}
+	// TODO: replace the service user token with the current user's token if it becomes possible to create the scicatJob as one's own user
+	//   , which will happen once the required changes are merged into BE SciCat. If the changes will still not allow this, just
+	//   remove this TODO.
+	scicatJob, err := CreateGlobusTransferScicatJob(ctx, tp.scicat, token, transfer)
+	if err != nil {
+		return transfer, &SubmissionError{Step: StepCreateJob, Err: err}
+	}
+	transfer.ScicatJobId = scicatJob.ID
+	transfer.Status = jobs.Submitting
+	tp.putTransfer(transfer)
+	return transfer, nil
+}
+
+// Remaining steps of the submission of a transfer whose job was created: submit
+// to globus and attach the task to the job
+func (tp TaskPool) CompleteSubmission(ctx context.Context, transfer store.Transfer, submit func(context.Context, store.Transfer) (string, error)) (_ store.Transfer, err error) {
+	ctx, span := tracing.Start(ctx, "tasks.CompleteSubmission", attribute.String("scicat.job_id", transfer.ScicatJobId))
+	defer func() { tracing.End(span, err) }()
+
+	transfer.GlobusTaskId, err = submit(ctx, transfer)
+	if err != nil {
+		return transfer, tp.failSubmission(ctx, transfer, StepSubmitGlobus, err)
+	}
+	tp.putTransfer(transfer)
+
+	token, err := tp.scicatServiceUser.GetToken()
+	if err == nil {
+		_, err = tp.scicat.UpdateJob(ctx, token, transfer.ScicatJobId, scicat.JobPatch{
+			StatusCode:    "001",
+			StatusMessage: "started",
+			JobResultObject: jobs.JobResultObject{
+				GlobusTaskId: transfer.GlobusTaskId,
+				Status:       jobs.Transferring,
+			},
+		})
+	}
+	if err != nil {
+		if _, cancelErr := globusclient.CancelTask(ctx, tp.globusClient, transfer.GlobusTaskId); cancelErr != nil {
+			slog.WarnContext(ctx, "couldn't cancel globus task of failed submission, leaving it to the reconciler", "jobId", transfer.ScicatJobId, "globusTaskId", transfer.GlobusTaskId, "error", cancelErr)
+			tp.compensations.Add(Compensation{
+				GlobusTaskId: transfer.GlobusTaskId,
+				ScicatJobId:  transfer.ScicatJobId,
+				Attempts:     1,
+				LastError:    cancelErr.Error(),
+			})
+		}
+		return transfer, tp.failSubmission(ctx, transfer, StepAttachTask, err)
+	}
+
+	transfer.Status = jobs.Transferring
+	tp.AddTransferTask(transfer)
+	return transfer, nil
+}
+
+// Complete the submission of a transfer whose job was created in the background. Failures are
+// recorded on the job, shutdown waits for the submission with WaitForSubmissions. The submission
+// continues the trace of ctx, but isn't cancelled with it.
+func (tp TaskPool) CompleteSubmissionAsync(ctx context.Context, transfer store.Transfer, submit func(context.Context, store.Transfer) (string, error)) {
+	ctx = context.WithoutCancel(ctx)
+	tp.submissions.Go(func() {
+		_, _ = tp.CompleteSubmission(ctx, transfer, submit)
+	})
+}
+
+// Record the failed step in the state store and on the job. The job update goes
+// through the outbox, as SciCat itself may be the reason of the failure.
+func (tp TaskPool) failSubmission(ctx context.Context, transfer store.Transfer, step string, err error) error {
+	slog.ErrorContext(ctx, "transfer submission failed", "jobId", transfer.ScicatJobId, "step", step, "error", err)
+	transfer.Status = jobs.SubmissionFailed
+	tp.putTransfer(transfer)
+	tp.outbox.Enqueue(ctx, transfer.ScicatJobId, "994", fmt.Sprintf("submission failed at step '%s'", step), jobs.JobResultObject{
+		GlobusTaskId: transfer.GlobusTaskId,
+		Status:       jobs.SubmissionFailed,
+		Error:        err.Error(),
+		FailedStep:   step,
+	}, true)
+	return &SubmissionError{Step: step, ScicatJobId: transfer.ScicatJobId, Err: err}
+}
+
+func (tp TaskPool) putTransfer(transfer store.Transfer) {
+	if err := tp.store.PutTransfer(transfer); err != nil {
+		slog.ErrorContext(transferContext(transfer), "couldn't record transfer in state store", "scicatJobId", transfer.ScicatJobId, "status", transfer.Status, "error", err)
+	}
+}
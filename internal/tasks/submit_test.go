@@ -0,0 +1,132 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/scicat"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/serviceuser"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+// SciCat server that logs in the service user and passes the other requests to handler
+func fakeScicat(t *testing.T, handler http.HandlerFunc) (*scicat.Client, serviceuser.ScicatServiceUser) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/auth/login":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600,"created":"` + time.Now().Format(time.RFC3339) + `"}`))
+		case "/api/v3/users/my/identity":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			handler(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	serviceUser, err := serviceuser.CreateServiceUser(server.URL+"/", "service", "password", server.Client())
+	assert.Nil(t, err)
+	return scicat.NewClient(server.URL+"/", config.NewScicatConfig(), http.DefaultTransport), serviceUser
+}
+
+func TestCompleteSubmission(t *testing.T) {
+	tests := []struct {
+		name      string
+		submitErr error
+		// status of the response to attaching the task to the job
+		attachStatus int
+		cancelStatus int
+		failedStep   string
+		cancelled    bool
+		compensated  bool
+	}{
+		{
+			name:       "globus submission fails",
+			submitErr:  errors.New("globus unavailable"),
+			failedStep: StepSubmitGlobus,
+		},
+		{
+			name:         "attach fails",
+			attachStatus: http.StatusForbidden,
+			cancelStatus: http.StatusOK,
+			failedStep:   StepAttachTask,
+			cancelled:    true,
+		},
+		{
+			name:         "attach and cancel fail",
+			attachStatus: http.StatusForbidden,
+			cancelStatus: http.StatusInternalServerError,
+			failedStep:   StepAttachTask,
+			cancelled:    true,
+			compensated:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			st := openTestStore(t, filepath.Join(t.TempDir(), "state.db"))
+			outbox, err := NewStoreOutbox(st, func(u JobUpdate) error { return nil })
+			assert.Nil(t, err)
+			compensations, err := NewCompensationQueue(st)
+			assert.Nil(t, err)
+			scicatClient, serviceUser := fakeScicat(t, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPatch, r.Method)
+				assert.Equal(t, "/api/v4/jobs/job1", r.URL.Path)
+				w.WriteHeader(test.attachStatus)
+				_, _ = w.Write([]byte(`{"message":"forbidden"}`))
+			})
+			cancelled := []string{}
+			globusClient := fakeGlobusClient(func(taskId string) int {
+				cancelled = append(cancelled, taskId)
+				return test.cancelStatus
+			})
+			tp := CreateTaskPool(scicatClient, globusClient, serviceUser, outbox, compensations, st, 1, 1, 1, 1)
+
+			transfer := store.Transfer{ScicatJobId: "job1", Status: jobs.Submitting}
+			_, err = tp.CompleteSubmission(context.Background(), transfer, func(context.Context, store.Transfer) (string, error) {
+				if test.submitErr != nil {
+					return "", test.submitErr
+				}
+				return "task1", nil
+			})
+
+			var submissionErr *SubmissionError
+			assert.ErrorAs(t, err, &submissionErr)
+			assert.Equal(t, test.failedStep, submissionErr.Step)
+			assert.Equal(t, "job1", submissionErr.ScicatJobId)
+
+			if test.cancelled {
+				assert.Equal(t, []string{"task1"}, cancelled)
+			} else {
+				assert.Empty(t, cancelled)
+			}
+			if test.compensated {
+				assert.Equal(t, 1, compensations.Len())
+				assert.Equal(t, "task1", compensations.pending[0].GlobusTaskId)
+				assert.Equal(t, 1, compensations.pending[0].Attempts)
+			} else {
+				assert.Equal(t, 0, compensations.Len())
+			}
+
+			stored, err := st.GetTransfer("job1")
+			assert.Nil(t, err)
+			assert.Equal(t, jobs.SubmissionFailed, stored.Status)
+
+			// the failure is recorded on the job through the outbox
+			assert.Equal(t, 1, outbox.Len())
+			update := outbox.pending[0]
+			assert.Equal(t, "job1", update.JobId)
+			assert.Equal(t, "994", update.StatusCode)
+			assert.True(t, update.Terminal)
+			assert.Equal(t, jobs.SubmissionFailed, update.Result.Status)
+			assert.Equal(t, test.failedStep, update.Result.FailedStep)
+		})
+	}
+}
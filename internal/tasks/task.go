@@ -1,70 +1,117 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/globusclient"
 	"github.com/SwissOpenEM/scicat-globus-proxy/internal/serviceuser"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/store"
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/tracing"
 	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
 	"github.com/paulscherrerinstitute/scicat-cli/v3/datasetIngestor"
 	"github.com/paulscherrerinstitute/scicat-cli/v3/datasetUtils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-type ArchivalJobInfo struct {
-	OwnerUser    string
-	OwnerGroup   string
-	AutoArchive  bool
-	ContactEmail string
-}
+type ArchivalJobInfo = jobs.ArchivalJobInfo
 
 type transferTask struct {
-	scicatUrl         *string
+	scicatUrl         string
+	scicatHttpClient  *http.Client
 	globusClient      globus.GlobusClient
 	scicatServiceUser serviceuser.ScicatServiceUser
+	outbox            *Outbox
+	store             *store.Store
 	globusTaskId      string
-	datasetPid        string
+	datasetPids       []string
 	scicatJobId       string
 	taskPollInterval  time.Duration
+	minUpdateInterval time.Duration
 	cancel            chan struct{}
+	stop              <-chan struct{}
 	cleanup           func()
 	archivalJobInfo   ArchivalJobInfo
+	// carries the request id and continues the trace of the request that created the transfer
+	ctx context.Context
 	// current status
 	bytesTransferred uint
 	filesTransferred uint
 	filesTotal       uint
+	// last status queued for SciCat
+	lastResult     jobs.JobResultObject
+	lastUpdateTime time.Time
 }
 
 func (t transferTask) execute() {
 	defer t.cleanup()
 
-	completed := false
-	var err error = nil
-
 	for {
-		select {
-		case <-t.cancel:
-			_ = t.cancelTask()
-			return
-		default:
+		completed, failed := t.updateTask()
+		if failed {
+			t.setStatus(jobs.Failed)
+			return // if the transfer failed, don't mark the dataset as archivable
 		}
-		completed, err = t.updateTask()
-		if completed || err != nil {
+		if completed {
 			break
 		}
-		time.Sleep(t.taskPollInterval)
+		if !t.waitForNextPoll() {
+			return
+		}
 	}
 
-	if !completed || err != nil {
-		return // if not completed or error'd, don't mark the dataset as archivable
-	}
 	t.finishTask()
+	t.setStatus(jobs.Finished)
 }
 
-func (t transferTask) updateTask() (bool, error) {
-	bytesTransferred, filesTransferred, totalFiles, completed, err := checkTransfer(t.globusClient, t.globusTaskId)
+// Wait for the next poll. Returns false if the task was cancelled, or if the pool
+// stopped, in which case the transfer is left unfinished for the next instance.
+func (t transferTask) waitForNextPoll() bool {
+	timer := time.NewTimer(t.taskPollInterval)
+	defer timer.Stop()
+	select {
+	case <-t.cancel:
+		t.cancelTask()
+		return false
+	case <-t.stop:
+		slog.DebugContext(t.ctx, "Stopped monitoring transfer", "jobId", t.scicatJobId, "globusTaskId", t.globusTaskId)
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Record the final state of the transfer in the state store
+func (t transferTask) setStatus(status jobs.JobStatus) {
+	if err := t.store.UpdateTransferStatus(t.scicatJobId, status); err != nil {
+		slog.ErrorContext(t.ctx, "couldn't update transfer in state store", "scicatJobId", t.scicatJobId, "status", status, "error", err)
+	}
+}
+
+// Record the progress queued for SciCat in the state store, so it can be resent
+func (t transferTask) setProgress(result jobs.JobResultObject) {
+	progress := store.Progress{
+		BytesTransferred: result.BytesTransferred,
+		FilesTransferred: result.FilesTransferred,
+		FilesTotal:       result.FilesTotal,
+	}
+	if err := t.store.UpdateTransferProgress(t.scicatJobId, progress); err != nil {
+		slog.ErrorContext(t.ctx, "couldn't update transfer in state store", "scicatJobId", t.scicatJobId, "error", err)
+	}
+}
+
+// Poll globus and queue the new status for SciCat. SciCat errors are handled by
+// the outbox, so only the transfer itself can make the task fail.
+func (t *transferTask) updateTask() (completed bool, failed bool) {
+	ctx, span := t.startSpan("transfer.Poll")
+	bytesTransferred, filesTransferred, totalFiles, completed, err := checkTransfer(ctx, t.globusClient, t.globusTaskId)
+	defer func() { tracing.End(span, err) }()
 
 	status := jobs.Transferring
 	statusCode := "002"
@@ -76,74 +123,86 @@ func (t transferTask) updateTask() (bool, error) {
 		statusCode = "998"
 		statusMessage = "an error has occured during task polling, this job is not updated anymore"
 		errMsg = err.Error()
-	} else if completed {
-		status = jobs.Finished
-		statusCode = "003"
-		statusMessage = "finished"
+	} else {
+		t.bytesTransferred = uint(bytesTransferred)
+		t.filesTransferred = uint(filesTransferred)
+		t.filesTotal = uint(totalFiles)
+		if completed {
+			status = jobs.Finished
+			statusCode = "003"
+			statusMessage = "finished"
+		}
 	}
 
-	taskLog(t.scicatJobId, t.globusTaskId, t.datasetPid, bytesTransferred, filesTransferred, totalFiles, status, err)
+	taskLog(ctx, t.scicatJobId, t.globusTaskId, t.datasetPids, bytesTransferred, filesTransferred, totalFiles, status, err)
+	span.SetAttributes(
+		attribute.String("transfer.status", string(status)),
+		attribute.Int("transfer.bytes_transferred", bytesTransferred),
+		attribute.Int("transfer.files_transferred", filesTransferred),
+	)
 
-	token, err := t.scicatServiceUser.GetToken()
-	if err != nil {
-		errFull := fmt.Errorf("getting token failed, task with scicat job id '%s', dataset pid '%s', globus id '%s' cannot be updated: %s", t.scicatJobId, t.datasetPid, t.globusTaskId, err.Error())
-		slog.Error(errFull.Error())
-		return false, errFull
+	result := jobs.JobResultObject{
+		GlobusTaskId:     t.globusTaskId,
+		BytesTransferred: uint(bytesTransferred),
+		FilesTransferred: uint(filesTransferred),
+		FilesTotal:       uint(totalFiles),
+		Status:           status,
+		Error:            errMsg,
+	}
+	now := time.Now()
+	if t.needsUpdate(result, now) {
+		t.outbox.Enqueue(ctx, t.scicatJobId, statusCode, statusMessage, result, status != jobs.Transferring)
+		t.lastResult = result
+		t.lastUpdateTime = now
+		t.setProgress(result)
 	}
 
-	_, err = UpdateGlobusTransferScicatJob(
-		*t.scicatUrl,
-		token,
-		t.scicatJobId,
-		statusCode,
-		statusMessage,
-		jobs.JobResultObject{
-			GlobusTaskId:     t.globusTaskId,
-			BytesTransferred: uint(bytesTransferred),
-			FilesTransferred: uint(filesTransferred),
-			FilesTotal:       uint(totalFiles),
-			Status:           status,
-			Error:            errMsg,
-		},
-	)
+	return completed, err != nil
+}
 
-	return completed, err
+// Skip updates that don't change the job, and limit the rate of progress updates.
+// State transitions are always written immediately.
+func (t *transferTask) needsUpdate(result jobs.JobResultObject, now time.Time) bool {
+	if result == t.lastResult {
+		return false
+	}
+	if result.Status != t.lastResult.Status {
+		return true
+	}
+	return now.Sub(t.lastUpdateTime) >= t.minUpdateInterval
+}
+
+// Start a span of the work on the transfer, in the trace of the request that created it
+func (t transferTask) startSpan(name string) (context.Context, trace.Span) {
+	return tracing.Start(t.ctx, name,
+		attribute.String("scicat.job_id", t.scicatJobId),
+		attribute.String("globus.task_id", t.globusTaskId),
+	)
 }
 
 func (t transferTask) finishTask() {
-	token, _ := t.scicatServiceUser.GetToken()
+	ctx, span := t.startSpan("transfer.Finish")
+	token, err := t.scicatServiceUser.GetToken()
+	defer func() { tracing.End(span, err) }()
+	if err != nil {
+		t.failFinishing(ctx, err)
+		return
+	}
 
-	scicatHost := *t.scicatUrl + "api/v3"
+	scicatHost := t.scicatUrl + "api/v3"
 
-	err := datasetIngestor.MarkFilesReady(http.DefaultClient, scicatHost, t.datasetPid, map[string]string{"accessToken": token})
-	if err != nil {
-		errMsg := err.Error()
-
-		_, err = UpdateGlobusTransferScicatJob(
-			*t.scicatUrl,
-			token,
-			t.scicatJobId,
-			"997",
-			"completed but can't mark dataset as archivable",
-			jobs.JobResultObject{
-				GlobusTaskId:     t.globusTaskId,
-				BytesTransferred: t.bytesTransferred,
-				FilesTransferred: t.filesTransferred,
-				FilesTotal:       t.filesTotal,
-				Status:           jobs.Finished,
-				Error:            errMsg,
-			},
-		)
+	for _, datasetPid := range t.datasetPids {
+		err = datasetIngestor.MarkFilesReady(t.scicatHttpClient, scicatHost, datasetPid, map[string]string{"accessToken": token})
 		if err != nil {
-			taskLog(t.scicatJobId, t.globusTaskId, t.datasetPid, int(t.bytesTransferred), int(t.filesTransferred), int(t.filesTotal), jobs.Finished, err)
+			t.failFinishing(ctx, err)
+			return
 		}
-		return
 	}
 
-	user, _, err := datasetUtils.AuthenticateUser(http.DefaultClient, scicatHost, *t.scicatServiceUser.Username, *t.scicatServiceUser.Password, false)
+	user, _, err := datasetUtils.AuthenticateUser(t.scicatHttpClient, scicatHost, *t.scicatServiceUser.Username, *t.scicatServiceUser.Password, false)
 
 	if err != nil {
-		taskLog(t.scicatJobId, t.globusTaskId, t.datasetPid, int(t.bytesTransferred), int(t.filesTransferred), int(t.filesTotal), jobs.Finished, err)
+		taskLog(ctx, t.scicatJobId, t.globusTaskId, t.datasetPids, int(t.bytesTransferred), int(t.filesTransferred), int(t.filesTotal), jobs.Finished, err)
 		return
 	}
 
@@ -156,21 +215,43 @@ func (t transferTask) finishTask() {
 	if t.archivalJobInfo.AutoArchive {
 		copies := 1
 		var executionTime time.Time // unspecified implies immediate execution
-		_, err = datasetUtils.CreateArchivalJob(http.DefaultClient, scicatHost, user, t.archivalJobInfo.OwnerGroup, []string{t.datasetPid}, &copies, &executionTime)
+		_, err = datasetUtils.CreateArchivalJob(t.scicatHttpClient, scicatHost, user, t.archivalJobInfo.OwnerGroup, t.datasetPids, &copies, &executionTime)
 		if err != nil {
-			taskLog(t.scicatJobId, t.globusTaskId, t.datasetPid, int(t.bytesTransferred), int(t.filesTransferred), int(t.filesTotal), jobs.Finished, err)
+			taskLog(ctx, t.scicatJobId, t.globusTaskId, t.datasetPids, int(t.bytesTransferred), int(t.filesTransferred), int(t.filesTotal), jobs.Finished, err)
 		}
 	}
 
 }
 
-func (t transferTask) cancelTask() error {
+// Record on the job that the transfer completed but the dataset couldn't be marked as archivable
+func (t transferTask) failFinishing(ctx context.Context, err error) {
+	taskLog(ctx, t.scicatJobId, t.globusTaskId, t.datasetPids, int(t.bytesTransferred), int(t.filesTransferred), int(t.filesTotal), jobs.Finished, err)
+	t.outbox.Enqueue(
+		ctx,
+		t.scicatJobId,
+		"997",
+		"completed but can't mark dataset as archivable",
+		jobs.JobResultObject{
+			GlobusTaskId:     t.globusTaskId,
+			BytesTransferred: t.bytesTransferred,
+			FilesTransferred: t.filesTransferred,
+			FilesTotal:       t.filesTotal,
+			Status:           jobs.Finished,
+			Error:            err.Error(),
+		},
+		true,
+	)
+}
+
+func (t transferTask) cancelTask() {
 	status := jobs.Cancelled
 	statusCode := "003"
 	statusMessage := "cancelled"
 	errMsg := ""
 
-	_, err := t.globusClient.TransferCancelTaskByID(t.globusTaskId)
+	ctx, span := t.startSpan("transfer.Cancel")
+	_, err := globusclient.CancelTask(ctx, t.globusClient, t.globusTaskId)
+	tracing.End(span, err)
 	if err != nil {
 		status = jobs.Failed
 		statusCode = "996"
@@ -178,15 +259,11 @@ func (t transferTask) cancelTask() error {
 		errMsg = "failed cancelling globus transfer task: " + err.Error()
 	}
 
-	token, err := t.scicatServiceUser.GetToken()
-	if err != nil {
-		taskLog(t.scicatJobId, t.globusTaskId, t.datasetPid, int(t.bytesTransferred), int(t.filesTransferred), int(t.filesTotal), jobs.Cancelled, err)
-		return err
-	}
+	taskLog(t.ctx, t.scicatJobId, t.globusTaskId, t.datasetPids, int(t.bytesTransferred), int(t.filesTransferred), int(t.filesTotal), status, err)
+	t.setStatus(status)
 
-	_, err = UpdateGlobusTransferScicatJob(
-		*t.scicatUrl,
-		token,
+	t.outbox.Enqueue(
+		t.ctx,
 		t.scicatJobId,
 		statusCode,
 		statusMessage,
@@ -198,13 +275,12 @@ func (t transferTask) cancelTask() error {
 			Status:           status,
 			Error:            errMsg,
 		},
+		true,
 	)
-
-	return err
 }
 
-func checkTransfer(client globus.GlobusClient, globusTaskId string) (bytesTransferred int, filesTransferred int, totalFiles int, completed bool, err error) {
-	globusTask, err := client.TransferGetTaskByID(globusTaskId)
+func checkTransfer(ctx context.Context, client globus.GlobusClient, globusTaskId string) (bytesTransferred int, filesTransferred int, totalFiles int, completed bool, err error) {
+	globusTask, err := globusclient.GetTask(ctx, client, globusTaskId)
 	if err != nil {
 		return 0, 0, 1, false, fmt.Errorf("globus: can't continue transfer because an error occured while polling the task \"%s\": %v", globusTaskId, err)
 	}
@@ -230,16 +306,17 @@ func checkTransfer(client globus.GlobusClient, globusTaskId string) (bytesTransf
 	}
 }
 
-func taskLog(scicatJobId string, globusTaskId string, datasetPid string, bytesTransferred int, filesTransferred int, totalFiles int, status jobs.JobStatus, err error) {
+func taskLog(ctx context.Context, scicatJobId string, globusTaskId string, datasetPids []string, bytesTransferred int, filesTransferred int, totalFiles int, status jobs.JobStatus, err error) {
 	errString := ""
 	if err != nil {
 		errString = err.Error()
 	}
-	slog.Info(
+	slog.InfoContext(
+		ctx,
 		"Task",
 		"scicat job", scicatJobId,
 		"globus task", globusTaskId,
-		"dataset", datasetPid,
+		"dataset", strings.Join(datasetPids, ","),
 		"bytes transferred", bytesTransferred,
 		"files transferred", filesTransferred,
 		"total files detected", totalFiles,
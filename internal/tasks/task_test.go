@@ -0,0 +1,52 @@
+package tasks
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeedsUpdate(t *testing.T) {
+	start := time.Now()
+	task := transferTask{minUpdateInterval: 30 * time.Second}
+
+	// first status is always written
+	assert.True(t, task.needsUpdate(progress(0), start))
+	task.lastResult = progress(0)
+	task.lastUpdateTime = start
+
+	// no-op updates are skipped
+	assert.False(t, task.needsUpdate(progress(0), start.Add(time.Hour)))
+
+	// progress is rate limited
+	assert.False(t, task.needsUpdate(progress(10), start.Add(10*time.Second)))
+	assert.True(t, task.needsUpdate(progress(10), start.Add(30*time.Second)))
+
+	// state transitions are written immediately
+	assert.True(t, task.needsUpdate(jobs.JobResultObject{Status: jobs.Finished}, start.Add(time.Second)))
+}
+
+func TestCancelFinishingTransfer(t *testing.T) {
+	tp := TaskPool{cancelTask: map[string]chan struct{}{}, cancelMutex: &sync.Mutex{}}
+	tp.cancelTask["job1"] = make(chan struct{}, 1)
+
+	// the monitor is finishing and doesn't read the cancels, eg. a cancel followed by a delete
+	cancelled := make(chan struct{})
+	go func() {
+		assert.NoError(t, tp.CancelTransferTask("job1"))
+		assert.NoError(t, tp.CancelTransferTask("job1"))
+		close(cancelled)
+	}()
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("cancelling blocked")
+	}
+
+	tp.removeMonitor("job1")
+	assert.False(t, tp.IsMonitored("job1"))
+	assert.Error(t, tp.CancelTransferTask("job1"))
+}
@@ -0,0 +1,95 @@
+// OpenTelemetry tracing of the proxy.
+//
+// Spans are exported with OTLP over HTTP when tracing is enabled. Otherwise the
+// global tracer provider is a no-op, and spans only carry the trace context, eg.
+// from a request to the background work it started.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SwissOpenEM/scicat-globus-proxy/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Name of the service and the tracer
+const ServiceName = "scicat-globus-proxy"
+
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Set up the global tracer provider. The returned function flushes and stops the exporter.
+func Setup(ctx context.Context, conf config.TracingConfig, version string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator)
+	if !conf.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// the endpoint defaults to the OTEL_EXPORTER_OTLP_* environment variables
+	opts := []otlptracehttp.Option{}
+	if conf.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(conf.Endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil && !errors.Is(err, resource.ErrSchemaURLConflict) {
+		return nil, err
+	}
+
+	sampleRatio := 1.0
+	if conf.SampleRatio != nil {
+		sampleRatio = *conf.SampleRatio
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start a span as a child of the span in the context
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(ServiceName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End a span, recording the error if it's not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// The W3C traceparent of the span in the context, or an empty string
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// Continue the trace of a W3C traceparent, eg. of the request that started a transfer
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Exponential backoff between the attempts of a retried operation
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// The delay after the given number of failed attempts, starting at 1
+func (b Backoff) Delay(attempts int) time.Duration {
+	delay := b.Initial
+	for i := 1; i < attempts && delay < b.Max; i++ {
+		delay *= 2
+	}
+	return min(delay, b.Max)
+}
+
+// Call fn until it succeeds or the context is done. onError, if not nil, is called
+// with every error and the delay until the next attempt. Returns the context error
+// joined with the last error of fn if the context is done first.
+func Retry(ctx context.Context, backoff Backoff, fn func() error, onError func(err error, delay time.Duration)) error {
+	for attempts := 1; ; attempts++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		delay := backoff.Delay(attempts)
+		if onError != nil {
+			onError(err, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package jobs
+
+// Standalone SciCat calls of the first versions of this package. The proxy itself uses its
+// internal SciCat client with retries, timeouts and tracing; these are kept so importers of
+// the package don't break.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Returned by GetJobById for unknown jobs
+//
+// Deprecated: kept for compatibility with GetJobById.
+type JobNotFoundErr struct {
+	msg string
+}
+
+func (e *JobNotFoundErr) Error() string {
+	return e.msg
+}
+
+type fullFacetReplyElement struct {
+	All []fullFacetInternalObject `json:"all"`
+}
+
+type fullFacetInternalObject struct {
+	TotalSets uint `json:"totalSets"`
+}
+
+// List the jobs matching a loopback filter
+//
+// Deprecated: kept for compatibility, it doesn't retry or time out.
+func GetJobList(scicatUrl string, scicatToken string, filter string) ([]ScicatJob, error) {
+	url, err := url.JoinPath(scicatUrl, "api", "v4", "jobs")
+	if err != nil {
+		return []ScicatJob{}, err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return []ScicatJob{}, err
+	}
+
+	q := req.URL.Query()
+	q.Set("filter", filter)
+	req.URL.RawQuery = q.Encode()
+
+	req.Header.Set("Authorization", "Bearer "+scicatToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return []ScicatJob{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 400 {
+		return []ScicatJob{}, fmt.Errorf("getting transfer job list failed: bad request - likely bad filter was passed")
+	}
+	if resp.StatusCode != 200 {
+		return []ScicatJob{}, fmt.Errorf("getting transfer job list failed with unknown error - status code %d, status %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return []ScicatJob{}, err
+	}
+
+	jobs := []ScicatJob{}
+	err = json.Unmarshal(body, &jobs)
+	return jobs, err
+}
+
+// Count the jobs matching a loopback filter
+//
+// Deprecated: kept for compatibility, it doesn't retry or time out.
+func GetJobCount(scicatUrl string, scicatToken string, filter string) (uint, error) {
+	url, err := url.JoinPath(scicatUrl, "api", "v4", "jobs", "fullfacet")
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	q := req.URL.Query()
+	q.Set("filter", filter)
+	req.URL.RawQuery = q.Encode()
+
+	req.Header.Set("Authorization", "Bearer "+scicatToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 400 {
+		return 0, fmt.Errorf("getting transfer job count failed: bad request - likely bad filter was passed")
+	}
+	if resp.StatusCode != 200 {
+		return 0, fmt.Errorf("getting transfer job count failed with unknown error - status code %d, status %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	result := []fullFacetReplyElement{}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(result) != 1 {
+		return 0, fmt.Errorf("server replied with an unexpected number of reply objects in its response")
+	}
+
+	if len(result[0].All) != 1 {
+		return 0, fmt.Errorf("the 'All' element in the server response contains an unexpected number of objects")
+	}
+
+	return result[0].All[0].TotalSets, nil
+}
+
+// Get a job. Fails with a *JobNotFoundErr if the job doesn't exist.
+//
+// Deprecated: kept for compatibility, it doesn't retry or time out.
+func GetJobById(scicatUrl string, scicatToken string, jobId string) (ScicatJob, error) {
+	url, err := url.JoinPath(scicatUrl, "api", "v4", "jobs", jobId)
+	if err != nil {
+		return ScicatJob{}, err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return ScicatJob{}, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+scicatToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ScicatJob{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 403 {
+		return ScicatJob{}, fmt.Errorf("user doesn't have the right to access this dataset or user credentials are invalid")
+	}
+	if resp.StatusCode == 400 {
+		b, _ := io.ReadAll(resp.Body)
+		if strings.Contains(string(b), "Invalid job id") {
+			return ScicatJob{}, &JobNotFoundErr{"Job not found"}
+		}
+	}
+	if resp.StatusCode != 200 {
+		return ScicatJob{}, fmt.Errorf("unknown error - statuscode: %d, status: %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ScicatJob{}, err
+	}
+
+	job := ScicatJob{}
+	err = json.Unmarshal(body, &job)
+	return job, err
+}
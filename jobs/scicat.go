@@ -1,31 +1,54 @@
 package jobs
 
-import (
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"strings"
-	"time"
-)
+import "time"
 
 type Dataset struct {
 	Pid   string   `json:"pid"`
 	Files []string `json:"files"`
 }
 
+// Information required to mark the datasets as archivable after the transfer
+type ArchivalJobInfo struct {
+	OwnerUser    string `json:"ownerUser"`
+	OwnerGroup   string `json:"ownerGroup"`
+	AutoArchive  bool   `json:"autoArchive"`
+	ContactEmail string `json:"contactEmail"`
+}
+
+type TransferFile struct {
+	Path      string `json:"path"`
+	IsSymlink bool   `json:"isSymlink"`
+}
+
+// Parameters the transfer was requested with
+type TransferParams struct {
+	SourceFacility      string         `json:"sourceFacility,omitempty"`
+	DestinationFacility string         `json:"destinationFacility,omitempty"`
+	SourcePath          string         `json:"sourcePath,omitempty"`
+	DestinationPath     string         `json:"destinationPath,omitempty"`
+	FileList            []TransferFile `json:"fileList,omitempty"`
+}
+
 type JobParams struct {
 	DatasetList []Dataset `json:"datasetList"`
+	// Only set for jobs created by newer versions of the proxy
+	ArchivalJobInfo *ArchivalJobInfo `json:"archivalJobInfo,omitempty"`
+	TransferParams  *TransferParams  `json:"transferParams,omitempty"`
+	// Version of the proxy that created the job
+	ProxyVersion string `json:"proxyVersion,omitempty"`
+	// Id of the request that created the job, to find its log records
+	RequestId string `json:"requestId,omitempty"`
 }
 
 type JobStatus string
 
 const (
-	Cancelled    JobStatus = "cancelled"
-	Failed       JobStatus = "failed"
-	Finished     JobStatus = "finished"
-	Transferring JobStatus = "transferring"
+	Cancelled        JobStatus = "cancelled"
+	Failed           JobStatus = "failed"
+	Finished         JobStatus = "finished"
+	Submitting       JobStatus = "submitting"
+	SubmissionFailed JobStatus = "submission_failed"
+	Transferring     JobStatus = "transferring"
 )
 
 type JobResultObject struct {
@@ -35,6 +58,8 @@ type JobResultObject struct {
 	FilesTotal       uint      `json:"filesTotal"`
 	Status           JobStatus `json:"status"`
 	Error            string    `json:"error"`
+	// The submission step that failed, if the status is submission_failed
+	FailedStep string `json:"failedStep,omitempty"`
 }
 
 type ScicatJob struct {
@@ -54,153 +79,3 @@ type ScicatJob struct {
 	ConfigVersion   string          `json:"configVersion"`
 	JobResultObject JobResultObject `json:"jobResultObject"`
 }
-
-type JobNotFoundErr struct {
-	msg string
-}
-
-func (e *JobNotFoundErr) Error() string {
-	return e.msg
-}
-
-type fullFacetReplyElement struct {
-	All []fullFacetInternalObject `json:"all"`
-}
-
-type fullFacetInternalObject struct {
-	TotalSets uint `json:"totalSets"`
-}
-
-func GetJobList(scicatUrl string, scicatToken string, filter string) ([]ScicatJob, error) {
-	url, err := url.JoinPath(scicatUrl, "api", "v4", "jobs")
-	if err != nil {
-		return []ScicatJob{}, err
-	}
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return []ScicatJob{}, err
-	}
-
-	q := req.URL.Query()
-	q.Set("filter", filter)
-	req.URL.RawQuery = q.Encode()
-
-	req.Header.Set("Authorization", "Bearer "+scicatToken)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return []ScicatJob{}, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == 400 {
-		return []ScicatJob{}, fmt.Errorf("getting transfer job list failed: bad request - likely bad filter was passed")
-	}
-	if resp.StatusCode != 200 {
-		return []ScicatJob{}, fmt.Errorf("getting transfer job list failed with unknown error - status code %d, status %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return []ScicatJob{}, err
-	}
-
-	jobs := []ScicatJob{}
-	err = json.Unmarshal(body, &jobs)
-	return jobs, err
-}
-
-func GetJobCount(scicatUrl string, scicatToken string, filter string) (uint, error) {
-	url, err := url.JoinPath(scicatUrl, "api", "v4", "jobs", "fullfacet")
-	if err != nil {
-		return 0, err
-	}
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return 0, err
-	}
-
-	q := req.URL.Query()
-	q.Set("filter", filter)
-	req.URL.RawQuery = q.Encode()
-
-	req.Header.Set("Authorization", "Bearer "+scicatToken)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == 400 {
-		return 0, fmt.Errorf("getting transfer job count failed: bad request - likely bad filter was passed")
-	}
-	if resp.StatusCode != 200 {
-		return 0, fmt.Errorf("getting transfer job count failed with unknown error - status code %d, status %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, err
-	}
-
-	result := []fullFacetReplyElement{}
-	err = json.Unmarshal(body, &result)
-	if err != nil {
-		return 0, err
-	}
-
-	if len(result) != 1 {
-		return 0, fmt.Errorf("server replied with an unexpected number of reply objects in its response")
-	}
-
-	if len(result[0].All) != 1 {
-		return 0, fmt.Errorf("the 'All' element in the server response contains an unexpected number of objects")
-	}
-
-	return result[0].All[0].TotalSets, nil
-}
-
-func GetJobById(scicatUrl string, scicatToken string, jobId string) (ScicatJob, error) {
-	url, err := url.JoinPath(scicatUrl, "api", "v4", "jobs", jobId)
-	if err != nil {
-		return ScicatJob{}, err
-	}
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return ScicatJob{}, err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+scicatToken)
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return ScicatJob{}, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == 403 {
-		return ScicatJob{}, fmt.Errorf("user doesn't have the right to access this dataset or user credentials are invalid")
-	}
-	if resp.StatusCode == 400 {
-		b, _ := io.ReadAll(resp.Body)
-		if strings.Contains(string(b), "Invalid job id") {
-			return ScicatJob{}, &JobNotFoundErr{"Job not found"}
-		}
-	}
-	if resp.StatusCode != 200 {
-		return ScicatJob{}, fmt.Errorf("unknown error - statuscode: %d, status: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return ScicatJob{}, err
-	}
-
-	job := ScicatJob{}
-	err = json.Unmarshal(body, &job)
-	return job, err
-}
@@ -0,0 +1,1945 @@
+// Package client provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.6.0 DO NOT EDIT.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/oapi-codegen/runtime"
+)
+
+const (
+	ScicatKeyAuthScopes = "ScicatKeyAuth.Scopes"
+)
+
+// Defines values for OrphanedJobAction.
+const (
+	OrphanedJobActionFail   OrphanedJobAction = "fail"
+	OrphanedJobActionReport OrphanedJobAction = "report"
+)
+
+// Valid indicates whether the value is a known member of the OrphanedJobAction enum.
+func (e OrphanedJobAction) Valid() bool {
+	switch e {
+	case OrphanedJobActionFail:
+		return true
+	case OrphanedJobActionReport:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for OrphanedTaskAction.
+const (
+	OrphanedTaskActionCancel OrphanedTaskAction = "cancel"
+	OrphanedTaskActionReport OrphanedTaskAction = "report"
+)
+
+// Valid indicates whether the value is a known member of the OrphanedTaskAction enum.
+func (e OrphanedTaskAction) Valid() bool {
+	switch e {
+	case OrphanedTaskActionCancel:
+		return true
+	case OrphanedTaskActionReport:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for RestoredTransferSource.
+const (
+	Scicat RestoredTransferSource = "scicat"
+	Store  RestoredTransferSource = "store"
+)
+
+// Valid indicates whether the value is a known member of the RestoredTransferSource enum.
+func (e RestoredTransferSource) Valid() bool {
+	switch e {
+	case Scicat:
+		return true
+	case Store:
+		return true
+	default:
+		return false
+	}
+}
+
+// Defines values for TransferItemStatus.
+const (
+	Cancelled        TransferItemStatus = "cancelled"
+	Failed           TransferItemStatus = "failed"
+	Finished         TransferItemStatus = "finished"
+	InvalidStatus    TransferItemStatus = "invalid status"
+	SubmissionFailed TransferItemStatus = "submission_failed"
+	Submitting       TransferItemStatus = "submitting"
+	Transferring     TransferItemStatus = "transferring"
+	Waiting          TransferItemStatus = "waiting"
+)
+
+// Valid indicates whether the value is a known member of the TransferItemStatus enum.
+func (e TransferItemStatus) Valid() bool {
+	switch e {
+	case Cancelled:
+		return true
+	case Failed:
+		return true
+	case Finished:
+		return true
+	case InvalidStatus:
+		return true
+	case SubmissionFailed:
+		return true
+	case Submitting:
+		return true
+	case Transferring:
+		return true
+	case Waiting:
+		return true
+	default:
+		return false
+	}
+}
+
+// Compensation cancellation of the globus task of a failed submission
+type Compensation struct {
+	// Attempts number of cancellation attempts so far
+	Attempts int `json:"attempts"`
+
+	// Error set if the cancellation failed again
+	Error        *string `json:"error,omitempty"`
+	GlobusTaskId string  `json:"globusTaskId"`
+	ScicatJobId  string  `json:"scicatJobId"`
+}
+
+// FileToTransfer the file to transfer as part of a transfer request
+type FileToTransfer struct {
+	// IsSymlink specifies whether this file is a symlink
+	IsSymlink bool `json:"isSymlink"`
+
+	// Path the path of the file, it has to be relative to the dataset source folder
+	Path string `json:"path"`
+}
+
+// HealthStatus the state of the proxy
+type HealthStatus struct {
+	// LastError the last error connecting to the dependency it's waiting for
+	LastError *string `json:"lastError,omitempty"`
+
+	// Ready whether the proxy accepts requests
+	Ready bool `json:"ready"`
+
+	// WaitingFor the dependency the proxy is waiting for while starting, eg. "SciCat service user" or "Globus client"
+	WaitingFor *string `json:"waitingFor,omitempty"`
+}
+
+// IdentityCacheFlush outcome of flushing the identity cache
+type IdentityCacheFlush struct {
+	// Flushed number of removed cache entries, including rejected tokens
+	Flushed int `json:"flushed"`
+}
+
+// OrphanedJob an unfinished SciCat job without a globus task
+type OrphanedJob struct {
+	// Action what was done with the job
+	Action      OrphanedJobAction `json:"action"`
+	Reason      string            `json:"reason"`
+	ScicatJobId string            `json:"scicatJobId"`
+}
+
+// OrphanedJobAction what was done with the job
+type OrphanedJobAction string
+
+// OrphanedTask an active globus task that belongs to no SciCat job
+type OrphanedTask struct {
+	// Action what was done with the task
+	Action OrphanedTaskAction `json:"action"`
+
+	// Error set if the action failed
+	Error        *string `json:"error,omitempty"`
+	GlobusTaskId string  `json:"globusTaskId"`
+	Label        string  `json:"label"`
+	Status       string  `json:"status"`
+}
+
+// OrphanedTaskAction what was done with the task
+type OrphanedTaskAction string
+
+// ReconcileReport outcome of one reconciliation run
+type ReconcileReport struct {
+	// Compensations retried cancellations of globus tasks whose submission failed
+	Compensations []Compensation `json:"compensations"`
+
+	// Error set if the reconciliation was aborted
+	Error *string `json:"error,omitempty"`
+
+	// Interrupted ids of jobs whose submission was interrupted before a globus task was attached
+	Interrupted   []string       `json:"interrupted"`
+	OrphanedJobs  []OrphanedJob  `json:"orphanedJobs"`
+	OrphanedTasks []OrphanedTask `json:"orphanedTasks"`
+
+	// Reattached ids of jobs whose transfer was not monitored and got a new monitor
+	Reattached []string `json:"reattached"`
+
+	// Resynced ids of jobs still transferring in SciCat although the transfer is done
+	Resynced []string `json:"resynced"`
+
+	// Time when the reconciliation ran
+	Time time.Time `json:"time"`
+}
+
+// RestoreSummary outcome of resuming unfinished transfers at startup
+type RestoreSummary struct {
+	// Error set if restoring was aborted
+	Error   *string            `json:"error,omitempty"`
+	Resumed []RestoredTransfer `json:"resumed"`
+	Skipped []SkippedTransfer  `json:"skipped"`
+
+	// Time when the transfers were restored
+	Time time.Time `json:"time"`
+}
+
+// RestoredTransfer a transfer that is monitored again
+type RestoredTransfer struct {
+	GlobusTaskId string `json:"globusTaskId"`
+	ScicatJobId  string `json:"scicatJobId"`
+
+	// Source where the transfer was restored from
+	Source RestoredTransferSource `json:"source"`
+}
+
+// RestoredTransferSource where the transfer was restored from
+type RestoredTransferSource string
+
+// SkippedTransfer an unfinished transfer that could not be resumed
+type SkippedTransfer struct {
+	Reason      string `json:"reason"`
+	ScicatJobId string `json:"scicatJobId"`
+}
+
+// TransferAccepted a transfer that is being submitted to globus in the background
+type TransferAccepted struct {
+	// JobId the SciCat job id of the transfer job
+	JobId string `json:"jobId"`
+
+	// StatusUrl path of the status of the transfer at the proxy
+	StatusUrl string `json:"statusUrl"`
+}
+
+// TransferItem defines model for TransferItem.
+type TransferItem struct {
+	BytesTotal          *int               `json:"bytesTotal,omitempty"`
+	BytesTransferred    *int               `json:"bytesTransferred,omitempty"`
+	CreatedAt           *time.Time         `json:"createdAt,omitempty"`
+	DatasetPids         *[]string          `json:"datasetPids,omitempty"`
+	DestinationFacility *string            `json:"destinationFacility,omitempty"`
+	FilesTotal          *int               `json:"filesTotal,omitempty"`
+	FilesTransferred    *int               `json:"filesTransferred,omitempty"`
+	GlobusTaskId        *string            `json:"globusTaskId,omitempty"`
+	Message             *string            `json:"message,omitempty"`
+	OwnerGroup          *string            `json:"ownerGroup,omitempty"`
+	OwnerUser           *string            `json:"ownerUser,omitempty"`
+	SourceFacility      *string            `json:"sourceFacility,omitempty"`
+	Status              TransferItemStatus `json:"status"`
+
+	// TransferId the SciCat job id of the transfer
+	TransferId string     `json:"transferId"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+}
+
+// TransferItemStatus defines model for TransferItem.Status.
+type TransferItemStatus string
+
+// TransferList a page of transfers
+type TransferList struct {
+	// Total number of transfers matching the filter
+	Total     int            `json:"total"`
+	Transfers []TransferItem `json:"transfers"`
+}
+
+// TransferStarted a transfer that was submitted to globus
+type TransferStarted struct {
+	// JobId the SciCat job id of the transfer job
+	JobId string `json:"jobId"`
+}
+
+// GeneralErrorResponse defines model for GeneralErrorResponse.
+type GeneralErrorResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+// ListTransfersParams defines parameters for ListTransfers.
+type ListTransfersParams struct {
+	// Status only list transfers with this status
+	Status *string `form:"status,omitempty" json:"status,omitempty"`
+
+	// Limit maximum number of transfers to return
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Offset number of transfers to skip
+	Offset *int `form:"offset,omitempty" json:"offset,omitempty"`
+}
+
+// PostTransferTaskJSONBody defines parameters for PostTransferTask.
+type PostTransferTaskJSONBody struct {
+	FileList *[]FileToTransfer `json:"fileList,omitempty"`
+}
+
+// PostTransferTaskParams defines parameters for PostTransferTask.
+type PostTransferTaskParams struct {
+	// SourceFacility the identifier name of the source facility
+	SourceFacility string `form:"sourceFacility" json:"sourceFacility"`
+
+	// DestFacility the path in the destination collection to use for the transfer
+	DestFacility string `form:"destFacility" json:"destFacility"`
+
+	// ScicatPid the pid of the dataset being transferred
+	ScicatPid string `form:"scicatPid" json:"scicatPid"`
+
+	// CollectionRootPath Path to the root of the globus collection on the source facility
+	CollectionRootPath string `form:"collectionRootPath" json:"collectionRootPath"`
+
+	// AutoArchive start archive job after successful transfer
+	AutoArchive *bool `form:"autoArchive,omitempty" json:"autoArchive,omitempty"`
+
+	// Async return as soon as the SciCat job is created, and submit the transfer to globus in the background. Failures are reported on the job with the status submission_failed
+	Async *bool `form:"async,omitempty" json:"async,omitempty"`
+
+	// IdempotencyKey unique key identifying this request. Retrying a request with the same key returns the job of the earlier request instead of starting a new transfer
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
+}
+
+// DeleteTransferTaskParams defines parameters for DeleteTransferTask.
+type DeleteTransferTaskParams struct {
+	// Delete Enables/disables deleting from scicat job system. By default, it's disabled (false).
+	Delete *bool `form:"delete,omitempty" json:"delete,omitempty"`
+}
+
+// PostTransferTaskJSONRequestBody defines body for PostTransferTask for application/json ContentType.
+type PostTransferTaskJSONRequestBody PostTransferTaskJSONBody
+
+// RequestEditorFn  is the function signature for the RequestEditor callback function
+type RequestEditorFn func(ctx context.Context, req *http.Request) error
+
+// Doer performs HTTP requests.
+//
+// The standard http.Client implements this interface.
+type HttpRequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client which conforms to the OpenAPI3 specification for this service.
+type Client struct {
+	// The endpoint of the server conforming to this interface, with scheme,
+	// https://api.deepmap.com for example. This can contain a path relative
+	// to the server, such as https://api.deepmap.com/dev-test, and all the
+	// paths in the swagger spec will be appended to the server.
+	Server string
+
+	// Doer for performing requests, typically a *http.Client with any
+	// customized settings, such as certificate chains.
+	Client HttpRequestDoer
+
+	// A list of callbacks for modifying requests which are generated before sending over
+	// the network.
+	RequestEditors []RequestEditorFn
+}
+
+// ClientOption allows setting custom parameters during construction
+type ClientOption func(*Client) error
+
+// Creates a new Client, with reasonable defaults
+func NewClient(server string, opts ...ClientOption) (*Client, error) {
+	// create a client with sane default values
+	client := Client{
+		Server: server,
+	}
+	// mutate client and add all optional params
+	for _, o := range opts {
+		if err := o(&client); err != nil {
+			return nil, err
+		}
+	}
+	// ensure the server URL always has a trailing slash
+	if !strings.HasSuffix(client.Server, "/") {
+		client.Server += "/"
+	}
+	// create httpClient, if not already present
+	if client.Client == nil {
+		client.Client = &http.Client{}
+	}
+	return &client, nil
+}
+
+// WithHTTPClient allows overriding the default Doer, which is
+// automatically created using http.Client. This is useful for tests.
+func WithHTTPClient(doer HttpRequestDoer) ClientOption {
+	return func(c *Client) error {
+		c.Client = doer
+		return nil
+	}
+}
+
+// WithRequestEditorFn allows setting up a callback function, which will be
+// called right before sending the request. This can be used to mutate the request.
+func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
+	return func(c *Client) error {
+		c.RequestEditors = append(c.RequestEditors, fn)
+		return nil
+	}
+}
+
+// The interface specification for the client above.
+type ClientInterface interface {
+	// FlushIdentityCache request
+	FlushIdentityCache(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetReconcileReport request
+	GetReconcileReport(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetRestoreSummary request
+	GetRestoreSummary(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetLiveness request
+	GetLiveness(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetReadiness request
+	GetReadiness(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// ListTransfers request
+	ListTransfers(ctx context.Context, params *ListTransfersParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PostTransferTaskWithBody request with any body
+	PostTransferTaskWithBody(ctx context.Context, params *PostTransferTaskParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	PostTransferTask(ctx context.Context, params *PostTransferTaskParams, body PostTransferTaskJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DeleteTransferTask request
+	DeleteTransferTask(ctx context.Context, scicatJobId string, params *DeleteTransferTaskParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetTransferTask request
+	GetTransferTask(ctx context.Context, scicatJobId string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetVersion request
+	GetVersion(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error)
+}
+
+func (c *Client) FlushIdentityCache(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewFlushIdentityCacheRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetReconcileReport(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetReconcileReportRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetRestoreSummary(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetRestoreSummaryRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetLiveness(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetLivenessRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetReadiness(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetReadinessRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) ListTransfers(ctx context.Context, params *ListTransfersParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewListTransfersRequest(c.Server, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostTransferTaskWithBody(ctx context.Context, params *PostTransferTaskParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostTransferTaskRequestWithBody(c.Server, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PostTransferTask(ctx context.Context, params *PostTransferTaskParams, body PostTransferTaskJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPostTransferTaskRequest(c.Server, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeleteTransferTask(ctx context.Context, scicatJobId string, params *DeleteTransferTaskParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteTransferTaskRequest(c.Server, scicatJobId, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetTransferTask(ctx context.Context, scicatJobId string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetTransferTaskRequest(c.Server, scicatJobId)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetVersion(ctx context.Context, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetVersionRequest(c.Server)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+// NewFlushIdentityCacheRequest generates requests for FlushIdentityCache
+func NewFlushIdentityCacheRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/admin/identity-cache")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetReconcileReportRequest generates requests for GetReconcileReport
+func NewGetReconcileReportRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/admin/reconcile")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetRestoreSummaryRequest generates requests for GetRestoreSummary
+func NewGetRestoreSummaryRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/admin/restore")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetLivenessRequest generates requests for GetLiveness
+func NewGetLivenessRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/health/live")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetReadinessRequest generates requests for GetReadiness
+func NewGetReadinessRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/health/ready")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewListTransfersRequest generates requests for ListTransfers
+func NewListTransfersRequest(server string, params *ListTransfersParams) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/transfer")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Status != nil {
+
+			if queryFrag, err := runtime.StyleParamWithOptions("form", true, "status", *params.Status, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationQuery, Type: "string", Format: ""}); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Limit != nil {
+
+			if queryFrag, err := runtime.StyleParamWithOptions("form", true, "limit", *params.Limit, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationQuery, Type: "integer", Format: ""}); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Offset != nil {
+
+			if queryFrag, err := runtime.StyleParamWithOptions("form", true, "offset", *params.Offset, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationQuery, Type: "integer", Format: ""}); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewPostTransferTaskRequest calls the generic PostTransferTask builder with application/json body
+func NewPostTransferTaskRequest(server string, params *PostTransferTaskParams, body PostTransferTaskJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewPostTransferTaskRequestWithBody(server, params, "application/json", bodyReader)
+}
+
+// NewPostTransferTaskRequestWithBody generates requests for PostTransferTask with any type of body
+func NewPostTransferTaskRequestWithBody(server string, params *PostTransferTaskParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/transfer")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if queryFrag, err := runtime.StyleParamWithOptions("form", true, "sourceFacility", params.SourceFacility, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationQuery, Type: "string", Format: ""}); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithOptions("form", true, "destFacility", params.DestFacility, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationQuery, Type: "string", Format: ""}); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithOptions("form", true, "scicatPid", params.ScicatPid, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationQuery, Type: "string", Format: ""}); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if queryFrag, err := runtime.StyleParamWithOptions("form", true, "collectionRootPath", params.CollectionRootPath, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationQuery, Type: "string", Format: ""}); err != nil {
+			return nil, err
+		} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+			return nil, err
+		} else {
+			for k, v := range parsed {
+				for _, v2 := range v {
+					queryValues.Add(k, v2)
+				}
+			}
+		}
+
+		if params.AutoArchive != nil {
+
+			if queryFrag, err := runtime.StyleParamWithOptions("form", true, "autoArchive", *params.AutoArchive, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationQuery, Type: "boolean", Format: ""}); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		if params.Async != nil {
+
+			if queryFrag, err := runtime.StyleParamWithOptions("form", true, "async", *params.Async, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationQuery, Type: "boolean", Format: ""}); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	if params != nil {
+
+		if params.IdempotencyKey != nil {
+			var headerParam0 string
+
+			headerParam0, err = runtime.StyleParamWithOptions("simple", false, "Idempotency-Key", *params.IdempotencyKey, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationHeader, Type: "string", Format: ""})
+			if err != nil {
+				return nil, err
+			}
+
+			req.Header.Set("Idempotency-Key", headerParam0)
+		}
+
+	}
+
+	return req, nil
+}
+
+// NewDeleteTransferTaskRequest generates requests for DeleteTransferTask
+func NewDeleteTransferTaskRequest(server string, scicatJobId string, params *DeleteTransferTaskParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithOptions("simple", false, "scicatJobId", scicatJobId, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationPath, Type: "string", Format: ""})
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/transfer/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if params != nil {
+		queryValues := queryURL.Query()
+
+		if params.Delete != nil {
+
+			if queryFrag, err := runtime.StyleParamWithOptions("form", true, "delete", *params.Delete, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationQuery, Type: "boolean", Format: ""}); err != nil {
+				return nil, err
+			} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+				return nil, err
+			} else {
+				for k, v := range parsed {
+					for _, v2 := range v {
+						queryValues.Add(k, v2)
+					}
+				}
+			}
+
+		}
+
+		queryURL.RawQuery = queryValues.Encode()
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetTransferTaskRequest generates requests for GetTransferTask
+func NewGetTransferTaskRequest(server string, scicatJobId string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithOptions("simple", false, "scicatJobId", scicatJobId, runtime.StyleParamOptions{ParamLocation: runtime.ParamLocationPath, Type: "string", Format: ""})
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/transfer/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetVersionRequest generates requests for GetVersion
+func NewGetVersionRequest(server string) (*http.Request, error) {
+	var err error
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/version")
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// FlushIdentityCacheWithResponse request
+	FlushIdentityCacheWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*FlushIdentityCacheResponse, error)
+
+	// GetReconcileReportWithResponse request
+	GetReconcileReportWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetReconcileReportResponse, error)
+
+	// GetRestoreSummaryWithResponse request
+	GetRestoreSummaryWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetRestoreSummaryResponse, error)
+
+	// GetLivenessWithResponse request
+	GetLivenessWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetLivenessResponse, error)
+
+	// GetReadinessWithResponse request
+	GetReadinessWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetReadinessResponse, error)
+
+	// ListTransfersWithResponse request
+	ListTransfersWithResponse(ctx context.Context, params *ListTransfersParams, reqEditors ...RequestEditorFn) (*ListTransfersResponse, error)
+
+	// PostTransferTaskWithBodyWithResponse request with any body
+	PostTransferTaskWithBodyWithResponse(ctx context.Context, params *PostTransferTaskParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostTransferTaskResponse, error)
+
+	PostTransferTaskWithResponse(ctx context.Context, params *PostTransferTaskParams, body PostTransferTaskJSONRequestBody, reqEditors ...RequestEditorFn) (*PostTransferTaskResponse, error)
+
+	// DeleteTransferTaskWithResponse request
+	DeleteTransferTaskWithResponse(ctx context.Context, scicatJobId string, params *DeleteTransferTaskParams, reqEditors ...RequestEditorFn) (*DeleteTransferTaskResponse, error)
+
+	// GetTransferTaskWithResponse request
+	GetTransferTaskWithResponse(ctx context.Context, scicatJobId string, reqEditors ...RequestEditorFn) (*GetTransferTaskResponse, error)
+
+	// GetVersionWithResponse request
+	GetVersionWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetVersionResponse, error)
+}
+
+type FlushIdentityCacheResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *IdentityCacheFlush
+	JSON401      *GeneralErrorResponse
+	JSON403      *GeneralErrorResponse
+	JSON500      *GeneralErrorResponse
+	JSON503      *GeneralErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r FlushIdentityCacheResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r FlushIdentityCacheResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetReconcileReportResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *ReconcileReport
+	JSON401      *GeneralErrorResponse
+	JSON403      *GeneralErrorResponse
+	JSON500      *GeneralErrorResponse
+	JSON503      *GeneralErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetReconcileReportResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetReconcileReportResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetRestoreSummaryResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *RestoreSummary
+	JSON401      *GeneralErrorResponse
+	JSON403      *GeneralErrorResponse
+	JSON500      *GeneralErrorResponse
+	JSON503      *GeneralErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetRestoreSummaryResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetRestoreSummaryResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetLivenessResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *HealthStatus
+}
+
+// Status returns HTTPResponse.Status
+func (r GetLivenessResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetLivenessResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetReadinessResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *HealthStatus
+	JSON503      *HealthStatus
+}
+
+// Status returns HTTPResponse.Status
+func (r GetReadinessResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetReadinessResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type ListTransfersResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *TransferList
+	JSON401      *GeneralErrorResponse
+	JSON500      *GeneralErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r ListTransfersResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r ListTransfersResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PostTransferTaskResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *TransferStarted
+	JSON202      *TransferAccepted
+	JSON400      *GeneralErrorResponse
+	JSON401      *GeneralErrorResponse
+	JSON403      *GeneralErrorResponse
+	JSON409      *GeneralErrorResponse
+	JSON500      *GeneralErrorResponse
+	JSON503      *GeneralErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r PostTransferTaskResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PostTransferTaskResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteTransferTaskResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON400      *GeneralErrorResponse
+	JSON401      *GeneralErrorResponse
+	JSON403      *GeneralErrorResponse
+	JSON500      *GeneralErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteTransferTaskResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteTransferTaskResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetTransferTaskResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *TransferItem
+	JSON400      *GeneralErrorResponse
+	JSON401      *GeneralErrorResponse
+	JSON403      *GeneralErrorResponse
+	JSON404      *GeneralErrorResponse
+	JSON500      *GeneralErrorResponse
+}
+
+// Status returns HTTPResponse.Status
+func (r GetTransferTaskResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetTransferTaskResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetVersionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *struct {
+		// Version the current version of the service
+		Version string `json:"version"`
+	}
+}
+
+// Status returns HTTPResponse.Status
+func (r GetVersionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetVersionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// FlushIdentityCacheWithResponse request returning *FlushIdentityCacheResponse
+func (c *ClientWithResponses) FlushIdentityCacheWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*FlushIdentityCacheResponse, error) {
+	rsp, err := c.FlushIdentityCache(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFlushIdentityCacheResponse(rsp)
+}
+
+// GetReconcileReportWithResponse request returning *GetReconcileReportResponse
+func (c *ClientWithResponses) GetReconcileReportWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetReconcileReportResponse, error) {
+	rsp, err := c.GetReconcileReport(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetReconcileReportResponse(rsp)
+}
+
+// GetRestoreSummaryWithResponse request returning *GetRestoreSummaryResponse
+func (c *ClientWithResponses) GetRestoreSummaryWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetRestoreSummaryResponse, error) {
+	rsp, err := c.GetRestoreSummary(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetRestoreSummaryResponse(rsp)
+}
+
+// GetLivenessWithResponse request returning *GetLivenessResponse
+func (c *ClientWithResponses) GetLivenessWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetLivenessResponse, error) {
+	rsp, err := c.GetLiveness(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetLivenessResponse(rsp)
+}
+
+// GetReadinessWithResponse request returning *GetReadinessResponse
+func (c *ClientWithResponses) GetReadinessWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetReadinessResponse, error) {
+	rsp, err := c.GetReadiness(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetReadinessResponse(rsp)
+}
+
+// ListTransfersWithResponse request returning *ListTransfersResponse
+func (c *ClientWithResponses) ListTransfersWithResponse(ctx context.Context, params *ListTransfersParams, reqEditors ...RequestEditorFn) (*ListTransfersResponse, error) {
+	rsp, err := c.ListTransfers(ctx, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseListTransfersResponse(rsp)
+}
+
+// PostTransferTaskWithBodyWithResponse request with arbitrary body returning *PostTransferTaskResponse
+func (c *ClientWithResponses) PostTransferTaskWithBodyWithResponse(ctx context.Context, params *PostTransferTaskParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PostTransferTaskResponse, error) {
+	rsp, err := c.PostTransferTaskWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostTransferTaskResponse(rsp)
+}
+
+func (c *ClientWithResponses) PostTransferTaskWithResponse(ctx context.Context, params *PostTransferTaskParams, body PostTransferTaskJSONRequestBody, reqEditors ...RequestEditorFn) (*PostTransferTaskResponse, error) {
+	rsp, err := c.PostTransferTask(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePostTransferTaskResponse(rsp)
+}
+
+// DeleteTransferTaskWithResponse request returning *DeleteTransferTaskResponse
+func (c *ClientWithResponses) DeleteTransferTaskWithResponse(ctx context.Context, scicatJobId string, params *DeleteTransferTaskParams, reqEditors ...RequestEditorFn) (*DeleteTransferTaskResponse, error) {
+	rsp, err := c.DeleteTransferTask(ctx, scicatJobId, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteTransferTaskResponse(rsp)
+}
+
+// GetTransferTaskWithResponse request returning *GetTransferTaskResponse
+func (c *ClientWithResponses) GetTransferTaskWithResponse(ctx context.Context, scicatJobId string, reqEditors ...RequestEditorFn) (*GetTransferTaskResponse, error) {
+	rsp, err := c.GetTransferTask(ctx, scicatJobId, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetTransferTaskResponse(rsp)
+}
+
+// GetVersionWithResponse request returning *GetVersionResponse
+func (c *ClientWithResponses) GetVersionWithResponse(ctx context.Context, reqEditors ...RequestEditorFn) (*GetVersionResponse, error) {
+	rsp, err := c.GetVersion(ctx, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetVersionResponse(rsp)
+}
+
+// ParseFlushIdentityCacheResponse parses an HTTP response from a FlushIdentityCacheWithResponse call
+func ParseFlushIdentityCacheResponse(rsp *http.Response) (*FlushIdentityCacheResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &FlushIdentityCacheResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest IdentityCacheFlush
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 403:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON403 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 503:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON503 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetReconcileReportResponse parses an HTTP response from a GetReconcileReportWithResponse call
+func ParseGetReconcileReportResponse(rsp *http.Response) (*GetReconcileReportResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetReconcileReportResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest ReconcileReport
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 403:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON403 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 503:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON503 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetRestoreSummaryResponse parses an HTTP response from a GetRestoreSummaryWithResponse call
+func ParseGetRestoreSummaryResponse(rsp *http.Response) (*GetRestoreSummaryResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetRestoreSummaryResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest RestoreSummary
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 403:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON403 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 503:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON503 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetLivenessResponse parses an HTTP response from a GetLivenessWithResponse call
+func ParseGetLivenessResponse(rsp *http.Response) (*GetLivenessResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetLivenessResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest HealthStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetReadinessResponse parses an HTTP response from a GetReadinessWithResponse call
+func ParseGetReadinessResponse(rsp *http.Response) (*GetReadinessResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetReadinessResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest HealthStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 503:
+		var dest HealthStatus
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON503 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseListTransfersResponse parses an HTTP response from a ListTransfersWithResponse call
+func ParseListTransfersResponse(rsp *http.Response) (*ListTransfersResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListTransfersResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest TransferList
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParsePostTransferTaskResponse parses an HTTP response from a PostTransferTaskWithResponse call
+func ParsePostTransferTaskResponse(rsp *http.Response) (*PostTransferTaskResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PostTransferTaskResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest TransferStarted
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 202:
+		var dest TransferAccepted
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON202 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 403:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON403 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 409:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON409 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 503:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON503 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteTransferTaskResponse parses an HTTP response from a DeleteTransferTaskWithResponse call
+func ParseDeleteTransferTaskResponse(rsp *http.Response) (*DeleteTransferTaskResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteTransferTaskResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 403:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON403 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetTransferTaskResponse parses an HTTP response from a GetTransferTaskWithResponse call
+func ParseGetTransferTaskResponse(rsp *http.Response) (*GetTransferTaskResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetTransferTaskResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest TransferItem
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 400:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON400 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 401:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON401 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 403:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON403 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 404:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON404 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 500:
+		var dest GeneralErrorResponse
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON500 = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetVersionResponse parses an HTTP response from a GetVersionWithResponse call
+func ParseGetVersionResponse(rsp *http.Response) (*GetVersionResponse, error) {
+	bodyBytes, err := io.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetVersionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest struct {
+			// Version the current version of the service
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	}
+
+	return response, nil
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTP header the proxy expects the SciCat token in
+const ScicatTokenHeader = "SciCat-API-Key"
+
+// Request editor authenticating a single request with a SciCat token
+func ScicatToken(token string) RequestEditorFn {
+	return func(ctx context.Context, req *http.Request) error {
+		req.Header.Set(ScicatTokenHeader, token)
+		return nil
+	}
+}
+
+// Client option authenticating all requests with a SciCat token
+func WithScicatToken(token string) ClientOption {
+	return WithRequestEditorFn(ScicatToken(token))
+}
+
+// Request editor setting the Idempotency-Key header, so that a transfer request can be retried safely
+func IdempotencyKey(key string) RequestEditorFn {
+	return func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Idempotency-Key", key)
+		return nil
+	}
+}
+
+// Construct a client of the proxy at server, authenticated with a SciCat token
+func NewAuthenticatedClient(server string, token string, opts ...ClientOption) (*ClientWithResponses, error) {
+	return NewClientWithResponses(server, append([]ClientOption{WithScicatToken(token)}, opts...)...)
+}
+
+// An unsuccessful response of the proxy
+type ResponseError struct {
+	StatusCode int
+	Message    string `json:"message"`
+	Details    string `json:"details"`
+}
+
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("status %d", e.StatusCode)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Details != "" {
+		msg += " (" + e.Details + ")"
+	}
+	return msg
+}
+
+// Check the status of a response, returns a *ResponseError if it's not successful
+func CheckResponse(statusCode int, body []byte) error {
+	if statusCode >= 200 && statusCode <= 299 {
+		return nil
+	}
+	respErr := &ResponseError{StatusCode: statusCode}
+	// errors of the request validation aren't general error responses
+	if json.Unmarshal(body, respErr) != nil || respErr.Message == "" {
+		respErr.Message = strings.TrimSpace(string(body))
+	}
+	return respErr
+}
+
+// Whether the transfer reached a final status. An invalid status doesn't change anymore either.
+func (s TransferItemStatus) IsDone() bool {
+	switch s {
+	case Finished, Failed, Cancelled, SubmissionFailed, InvalidStatus:
+		return true
+	default:
+		return false
+	}
+}
+
+// Poll a transfer until it reaches a final status or the context is done. onUpdate
+// is called with every polled status, if it's not nil. Network errors, server errors and
+// rate limits are retried; other client errors, eg. an unknown job, and an invalid status
+// end the polling.
+func WaitForTransfer(ctx context.Context, c ClientWithResponsesInterface, scicatJobId string, interval time.Duration, onUpdate func(TransferItem)) (TransferItem, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		resp, err := c.GetTransferTaskWithResponse(ctx, scicatJobId)
+		if err == nil {
+			err = CheckResponse(resp.StatusCode(), resp.Body)
+		}
+		var respErr *ResponseError
+		switch {
+		case errors.As(err, &respErr) && respErr.StatusCode < 500 && respErr.StatusCode != http.StatusTooManyRequests:
+			return TransferItem{}, err
+		case err != nil:
+			lastErr = err
+		case resp.JSON200 == nil:
+			return TransferItem{}, fmt.Errorf("unexpected response: %s", resp.Status())
+		default:
+			lastErr = nil
+			if onUpdate != nil {
+				onUpdate(*resp.JSON200)
+			}
+			if resp.JSON200.Status == InvalidStatus {
+				return *resp.JSON200, fmt.Errorf("transfer %s has an invalid status", scicatJobId)
+			}
+			if resp.JSON200.Status.IsDone() {
+				return *resp.JSON200, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return TransferItem{}, fmt.Errorf("%w, last error: %w", ctx.Err(), lastErr)
+			}
+			return TransferItem{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckResponse(t *testing.T) {
+	assert.Nil(t, CheckResponse(http.StatusAccepted, nil))
+
+	err := CheckResponse(http.StatusForbidden, []byte(`{"message": "invalid source facility", "details": "facility: PSI"}`))
+	var respErr *ResponseError
+	assert.True(t, errors.As(err, &respErr))
+	assert.Equal(t, http.StatusForbidden, respErr.StatusCode)
+	assert.EqualError(t, err, "status 403: invalid source facility (facility: PSI)")
+
+	err = CheckResponse(http.StatusBadRequest, []byte("parameter \"limit\" in query has an error\n"))
+	assert.EqualError(t, err, "status 400: parameter \"limit\" in query has an error")
+}
+
+func TestWaitForTransfer(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/transfer/job1", r.URL.Path)
+		assert.Equal(t, "token", r.Header.Get(ScicatTokenHeader))
+		w.Header().Set("Content-Type", "application/json")
+		polls++
+		switch polls {
+		case 1:
+			_, _ = w.Write([]byte(`{"transferId": "job1", "status": "submitting"}`))
+		case 2:
+			// server errors and rate limits are retried
+			w.WriteHeader(http.StatusInternalServerError)
+		case 3:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 4:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"transferId": "job1", "status": "finished", "filesTransferred": 3}`))
+		}
+	}))
+	defer server.Close()
+
+	proxy, err := NewAuthenticatedClient(server.URL, "token")
+	assert.Nil(t, err)
+	updates := []TransferItemStatus{}
+	item, err := WaitForTransfer(context.Background(), proxy, "job1", time.Millisecond, func(item TransferItem) {
+		updates = append(updates, item.Status)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, Finished, item.Status)
+	assert.Equal(t, 3, *item.FilesTransferred)
+	assert.Equal(t, []TransferItemStatus{Submitting, Finished}, updates)
+	assert.Equal(t, 5, polls)
+}
+
+func TestWaitForTransferStopsOnClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "the requested job does not exist"}`))
+	}))
+	defer server.Close()
+
+	proxy, err := NewAuthenticatedClient(server.URL, "token")
+	assert.Nil(t, err)
+	_, err = WaitForTransfer(context.Background(), proxy, "unknown", time.Millisecond, nil)
+	assert.EqualError(t, err, "status 404: the requested job does not exist")
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"transferId": "job1", "status": "invalid status"}`))
+	})
+	item, err := WaitForTransfer(context.Background(), proxy, "job1", time.Millisecond, nil)
+	assert.EqualError(t, err, "transfer job1 has an invalid status")
+	assert.Equal(t, InvalidStatus, item.Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	_, err = WaitForTransfer(ctx, proxy, "job1", time.Millisecond, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// job ids are escaped as a single path segment
+		assert.Equal(t, "/transfer/job%2F1", r.URL.EscapedPath())
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "you don't have the right to see this job"}`))
+	}))
+	defer server.Close()
+
+	proxy, err := NewAuthenticatedClient(server.URL, "token")
+	assert.Nil(t, err)
+	resp, err := proxy.GetTransferTaskWithResponse(context.Background(), "job/1")
+	assert.Nil(t, err)
+	err = CheckResponse(resp.StatusCode(), resp.Body)
+	var respErr *ResponseError
+	assert.True(t, errors.As(err, &respErr))
+	assert.Equal(t, http.StatusForbidden, respErr.StatusCode)
+	assert.EqualError(t, err, "status 403: you don't have the right to see this job")
+}